@@ -0,0 +1,236 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the error the promises of
+// an open CircuitBreaker are rejected with
+var ErrCircuitOpen = errors.New("Circuit breaker is open")
+
+// BreakerState is the state
+// of a CircuitBreaker
+type BreakerState int
+
+const (
+	// BreakerClosed is the state of a circuit
+	// breaker that lets every call through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen is the state of a circuit
+	// breaker that rejects every call
+	BreakerOpen
+	// BreakerHalfOpen is the state of a circuit
+	// breaker that lets a few trial calls through
+	// to find out if it can close again
+	BreakerHalfOpen
+)
+
+// String
+// returns the state's name
+func (state BreakerState) String() string {
+	switch state {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerOptions configures
+// a CircuitBreaker
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of failures
+	// in a row that opens the breaker, 5 if zero
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open
+	// before it half-opens, 30 seconds if zero
+	Cooldown time.Duration
+	// HalfOpenCalls is the number of trial calls
+	// a half-open breaker lets through at once
+	// and that have to succeed to close it,
+	// 1 if zero
+	HalfOpenCalls int
+	// IsFailure tells whether an error counts as
+	// a failure, by default every error but the
+	// cancellation of the promise does
+	IsFailure func(err error) bool
+	// OnStateChange is called when
+	// the breaker changes state
+	OnStateChange func(from, to BreakerState)
+}
+
+// CircuitBreaker stops calling a failing
+// dependency for a while, so it can recover
+// and the callers fail fast.
+// It's safe to use from any go routine
+type CircuitBreaker struct {
+	options CircuitBreakerOptions
+	mutex   sync.Mutex
+	state   BreakerState
+	// generation changes with the state so
+	// the results of calls made in a previous
+	// state are ignored
+	generation uint64
+	// failures in a row while closed
+	failures int
+	// trials are the calls let through
+	// while half-open
+	trials int
+	// successes of the trial calls
+	successes int
+}
+
+// NewCircuitBreaker
+// Creates a closed CircuitBreaker
+func NewCircuitBreaker(options CircuitBreakerOptions) *CircuitBreaker {
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = 5
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = 30 * time.Second
+	}
+	if options.HalfOpenCalls <= 0 {
+		options.HalfOpenCalls = 1
+	}
+	if options.IsFailure == nil {
+		options.IsFailure = func(err error) bool {
+			var cancelled *CancelledError
+			return !errors.As(err, &cancelled)
+		}
+	}
+	return &CircuitBreaker{options: options}
+}
+
+// Do
+// calls factory and returns a promise that
+// settles like the promise it creates, and
+// counts its result, a panic of factory
+// counts as a failure. Cancelling the returned
+// promise cancels the promise factory created.
+// It doesn't call factory and returns a promise
+// rejected with ErrCircuitOpen while the
+// breaker is open
+func Do[T any](breaker *CircuitBreaker, factory func() *Promise[T]) *Promise[T] {
+	generation, ok := breaker.allow()
+	if !ok {
+		return rejected[T](ErrCircuitOpen)
+	}
+	promise := callFactory(factory)
+	deferred := NewDeferred[T]()
+	deferred.promise.OnCancel(func() {
+		promise.cancel(deferred.promise.cancelledWith())
+	})
+	// the result is recorded before the
+	// returned promise settles
+	tap(promise, func(obj T, err error) {
+		breaker.record(generation, err)
+		deferred.promise.settle(obj, err)
+	})
+	return deferred.promise
+}
+
+// State
+// returns the state of the breaker
+func (breaker *CircuitBreaker) State() BreakerState {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	return breaker.state
+}
+
+// allow
+// returns the generation of a call and
+// whether the breaker lets it through
+func (breaker *CircuitBreaker) allow() (uint64, bool) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	switch breaker.state {
+	case BreakerOpen:
+		return 0, false
+	case BreakerHalfOpen:
+		if breaker.trials >= breaker.options.HalfOpenCalls {
+			return 0, false
+		}
+		breaker.trials++
+	}
+	return breaker.generation, true
+}
+
+// record
+// counts the result of a call
+func (breaker *CircuitBreaker) record(generation uint64, err error) {
+	breaker.mutex.Lock()
+	if generation != breaker.generation {
+		breaker.mutex.Unlock()
+		return
+	}
+	failed := err != nil && breaker.options.IsFailure(err)
+	// errors that aren't failures
+	// don't count either way
+	ignored := err != nil && !failed
+	from := breaker.state
+	switch {
+	case breaker.state == BreakerHalfOpen && ignored:
+		breaker.trials--
+	case ignored:
+	case breaker.state == BreakerClosed && failed:
+		breaker.failures++
+		if breaker.failures >= breaker.options.FailureThreshold {
+			breaker.open()
+		}
+	case breaker.state == BreakerClosed:
+		breaker.failures = 0
+	case breaker.state == BreakerHalfOpen && failed:
+		breaker.open()
+	case breaker.state == BreakerHalfOpen:
+		breaker.successes++
+		if breaker.successes >= breaker.options.HalfOpenCalls {
+			breaker.transition(BreakerClosed)
+		}
+	}
+	to := breaker.state
+	breaker.mutex.Unlock()
+	breaker.changed(from, to)
+}
+
+// open
+// opens the breaker and half-opens it
+// after the cooldown
+func (breaker *CircuitBreaker) open() {
+	breaker.transition(BreakerOpen)
+	generation := breaker.generation
+	currentClock().AfterFunc(breaker.options.Cooldown, func() {
+		breaker.mutex.Lock()
+		if generation != breaker.generation {
+			breaker.mutex.Unlock()
+			return
+		}
+		breaker.transition(BreakerHalfOpen)
+		breaker.mutex.Unlock()
+		breaker.changed(BreakerOpen, BreakerHalfOpen)
+	})
+}
+
+// transition
+// moves the breaker to state and resets
+// its counts, the caller holds the mutex
+func (breaker *CircuitBreaker) transition(state BreakerState) {
+	breaker.state = state
+	breaker.generation++
+	breaker.failures = 0
+	breaker.trials = 0
+	breaker.successes = 0
+}
+
+// changed
+// calls OnStateChange if
+// the state changed
+func (breaker *CircuitBreaker) changed(from, to BreakerState) {
+	if from != to && breaker.options.OnStateChange != nil {
+		breaker.options.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,136 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	failing := func() *Promise[string] {
+		return Promisify[string](func() (string, error) {
+			return "", errors.New("Famous people don't shake hands")
+		})
+	}
+	succeeding := func() *Promise[string] {
+		return Promisify[string]("Someone famous")
+	}
+
+	t.Run("Opens after the failure threshold and rejects fast", func(t *testing.T) {
+		useTestClock(t)
+		changes := make([]string, 0)
+		breaker := NewCircuitBreaker(CircuitBreakerOptions{
+			FailureThreshold: 2,
+			Cooldown:         time.Minute,
+			OnStateChange: func(from, to BreakerState) {
+				changes = append(changes, from.String()+" -> "+to.String())
+			},
+		})
+		_, err := Do(breaker, failing).Await()
+		assert.EqualError(t, err, "Famous people don't shake hands")
+		assert.Equal(t, breaker.State(), BreakerClosed)
+		Do(breaker, failing).Await()
+		assert.Equal(t, breaker.State(), BreakerOpen)
+
+		called := false
+		_, err = Do(breaker, func() *Promise[string] {
+			called = true
+			return succeeding()
+		}).Await()
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.False(t, called)
+		assert.Equal(t, changes, []string{"closed -> open"})
+	})
+	t.Run("Resets the failures on success", func(t *testing.T) {
+		useTestClock(t)
+		breaker := NewCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 2})
+		Do(breaker, failing).Await()
+		Do(breaker, succeeding).Await()
+		Do(breaker, failing).Await()
+		assert.Equal(t, breaker.State(), BreakerClosed)
+	})
+	t.Run("Half-opens after the cooldown", func(t *testing.T) {
+		clock := useTestClock(t)
+		changes := make(chan string, 10)
+		breaker := NewCircuitBreaker(CircuitBreakerOptions{
+			FailureThreshold: 1,
+			Cooldown:         time.Minute,
+			OnStateChange: func(from, to BreakerState) {
+				changes <- from.String() + " -> " + to.String()
+			},
+		})
+		Do(breaker, failing).Await()
+		assert.Equal(t, <-changes, "closed -> open")
+		clock.Advance(59 * time.Second)
+		assert.Equal(t, breaker.State(), BreakerOpen)
+		clock.Advance(time.Second)
+		assert.Equal(t, breaker.State(), BreakerHalfOpen)
+		assert.Equal(t, <-changes, "open -> half-open")
+
+		// only one trial call is let through
+		trial := NewDeferred[string]()
+		p := Do(breaker, trial.Promise)
+		_, err := Do(breaker, succeeding).Await()
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		trial.Resolve("Someone famous")
+		p.Await()
+		assert.Equal(t, breaker.State(), BreakerClosed)
+		assert.Equal(t, <-changes, "half-open -> closed")
+	})
+	t.Run("Opens again when a trial call fails", func(t *testing.T) {
+		clock := useTestClock(t)
+		breaker := NewCircuitBreaker(CircuitBreakerOptions{
+			FailureThreshold: 1,
+			Cooldown:         time.Minute,
+		})
+		Do(breaker, failing).Await()
+		clock.Advance(time.Minute)
+		Do(breaker, failing).Await()
+		assert.Equal(t, breaker.State(), BreakerOpen)
+		clock.Advance(time.Minute)
+		assert.Equal(t, breaker.State(), BreakerHalfOpen)
+	})
+	t.Run("Counts a panicking factory as a failed trial call", func(t *testing.T) {
+		clock := useTestClock(t)
+		breaker := NewCircuitBreaker(CircuitBreakerOptions{
+			FailureThreshold: 1,
+			Cooldown:         time.Minute,
+		})
+		Do(breaker, failing).Await()
+		clock.Advance(time.Minute)
+		_, err := Do(breaker, func() *Promise[string] {
+			panic("Famous people don't shake hands")
+		}).Await()
+		var panicErr *PanicError
+		assert.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, panicErr.Value, "Famous people don't shake hands")
+		assert.Equal(t, breaker.State(), BreakerOpen)
+		clock.Advance(time.Minute)
+		Do(breaker, succeeding).Await()
+		assert.Equal(t, breaker.State(), BreakerClosed)
+	})
+	t.Run("Ignores cancellations and results of previous states", func(t *testing.T) {
+		clock := useTestClock(t)
+		breaker := NewCircuitBreaker(CircuitBreakerOptions{
+			FailureThreshold: 1,
+			Cooldown:         time.Minute,
+		})
+		late := NewDeferred[string]()
+		p := Do(breaker, late.Promise)
+		Do(breaker, failing).Await()
+		late.Reject(errors.New("Famous people don't shake hands"))
+		p.Await()
+		clock.Advance(time.Minute)
+		assert.Equal(t, breaker.State(), BreakerHalfOpen)
+
+		cancelled := NewDeferred[string]()
+		p = Do(breaker, cancelled.Promise)
+		cancelled.Promise().Cancel(nil)
+		p.Await()
+		assert.Equal(t, breaker.State(), BreakerHalfOpen)
+		Do(breaker, succeeding).Await()
+		assert.Equal(t, breaker.State(), BreakerClosed)
+	})
+}
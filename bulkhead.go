@@ -0,0 +1,193 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBulkheadFull is the error the promises of
+// a Bulkhead are rejected with when its queue
+// is full
+var ErrBulkheadFull = errors.New("Bulkhead is full")
+
+// Bulkhead caps the number of promises of a
+// dependency that are in flight at once,
+// the calls beyond the cap wait in a
+// bounded queue.
+// It's safe to use from any go routine
+type Bulkhead struct {
+	mutex sync.Mutex
+	// limit of promises in flight
+	limit int
+	// queueLimit is the number of calls
+	// that can wait for the bulkhead
+	queueLimit int
+	inFlight   int
+	// queue of the calls that wait
+	// for a promise to settle
+	queue []*bulkheadCall
+}
+
+// bulkheadCall is a call that
+// waits for the bulkhead
+type bulkheadCall struct {
+	start func()
+	// stop stops watching the
+	// context of the queued call
+	stop func() bool
+}
+
+// NewBulkhead
+// Creates a Bulkhead that lets limit
+// promises in flight at once and queues
+// up to queueLimit calls beyond them
+func NewBulkhead(limit int, queueLimit int) *Bulkhead {
+	if limit <= 0 {
+		panic("Bulkhead limit has to be positive")
+	}
+	return &Bulkhead{
+		limit:      limit,
+		queueLimit: queueLimit,
+	}
+}
+
+// Run
+// calls factory once the bulkhead has room
+// and returns a promise that settles like the
+// promise it creates, see RunContext
+func Run[T any](bulkhead *Bulkhead, factory func() *Promise[T]) *Promise[T] {
+	return RunContext(context.Background(), bulkhead, factory)
+}
+
+// RunContext
+// calls factory once the bulkhead has room
+// and returns a promise that settles like the
+// promise it creates. The returned promise is
+// rejected with ErrBulkheadFull if the queue is
+// full, and cancelled if ctx is done while it's
+// queued. Cancelling the returned promise
+// removes it from the queue or cancels
+// the promise factory created, its place is
+// only released once that promise's
+// step finished running
+func RunContext[T any](ctx context.Context, bulkhead *Bulkhead, factory func() *Promise[T]) *Promise[T] {
+	deferred := NewDeferred[T]()
+	call := &bulkheadCall{}
+	call.start = func() {
+		deferred.follow(func() *Promise[T] {
+			// the place is released once the work
+			// is done, not when the returned
+			// promise is cancelled
+			promise := callFactory(factory)
+			promise.cancellation.onFinish(bulkhead.release)
+			return promise
+		}, func() {})
+	}
+	started, queued := bulkhead.acquire(call)
+	switch {
+	case started:
+		call.start()
+	case queued:
+		bulkhead.watch(call, context.AfterFunc(ctx, func() {
+			if bulkhead.remove(call) {
+				deferred.promise.Cancel(context.Cause(ctx))
+			}
+		}))
+		deferred.promise.OnCancel(func() {
+			bulkhead.remove(call)
+		})
+	default:
+		deferred.Reject(ErrBulkheadFull)
+	}
+	return deferred.promise
+}
+
+// InFlight
+// returns the number of promises
+// of the bulkhead in flight
+func (bulkhead *Bulkhead) InFlight() int {
+	bulkhead.mutex.Lock()
+	defer bulkhead.mutex.Unlock()
+	return bulkhead.inFlight
+}
+
+// QueueLength
+// returns the number of calls
+// waiting for the bulkhead
+func (bulkhead *Bulkhead) QueueLength() int {
+	bulkhead.mutex.Lock()
+	defer bulkhead.mutex.Unlock()
+	return len(bulkhead.queue)
+}
+
+// acquire
+// returns whether call can start right
+// away or was queued
+func (bulkhead *Bulkhead) acquire(call *bulkheadCall) (started bool, queued bool) {
+	bulkhead.mutex.Lock()
+	defer bulkhead.mutex.Unlock()
+	if bulkhead.inFlight < bulkhead.limit {
+		bulkhead.inFlight++
+		return true, false
+	}
+	if len(bulkhead.queue) < bulkhead.queueLimit {
+		bulkhead.queue = append(bulkhead.queue, call)
+		return false, true
+	}
+	return false, false
+}
+
+// watch
+// keeps stop to stop watching the context
+// of call once it leaves the queue
+func (bulkhead *Bulkhead) watch(call *bulkheadCall, stop func() bool) {
+	bulkhead.mutex.Lock()
+	for _, queued := range bulkhead.queue {
+		if queued == call {
+			call.stop = stop
+			bulkhead.mutex.Unlock()
+			return
+		}
+	}
+	bulkhead.mutex.Unlock()
+	stop()
+}
+
+// remove
+// removes call from the queue, returns
+// false if it isn't queued anymore
+func (bulkhead *Bulkhead) remove(call *bulkheadCall) bool {
+	bulkhead.mutex.Lock()
+	defer bulkhead.mutex.Unlock()
+	for i, queued := range bulkhead.queue {
+		if queued == call {
+			bulkhead.queue = append(bulkhead.queue[:i], bulkhead.queue[i+1:]...)
+			if call.stop != nil {
+				call.stop()
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// release
+// hands the place of a finished promise
+// over to the next queued call
+func (bulkhead *Bulkhead) release() {
+	bulkhead.mutex.Lock()
+	if len(bulkhead.queue) == 0 {
+		bulkhead.inFlight--
+		bulkhead.mutex.Unlock()
+		return
+	}
+	call := bulkhead.queue[0]
+	bulkhead.queue = bulkhead.queue[1:]
+	stop := call.stop
+	bulkhead.mutex.Unlock()
+	if stop != nil {
+		stop()
+	}
+	call.start()
+}
@@ -0,0 +1,140 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkhead(t *testing.T) {
+	t.Run("Queues the calls beyond the limit", func(t *testing.T) {
+		bulkhead := NewBulkhead(2, 1)
+		deferreds := []*Deferred[string]{NewDeferred[string](), NewDeferred[string](), NewDeferred[string]()}
+		calls := make(chan int, 3)
+		promises := make([]*Promise[string], 0)
+		for i := range deferreds {
+			i := i
+			promises = append(promises, Run(bulkhead, func() *Promise[string] {
+				calls <- i
+				return deferreds[i].Promise()
+			}))
+		}
+		assert.Equal(t, bulkhead.InFlight(), 2)
+		assert.Equal(t, bulkhead.QueueLength(), 1)
+		assert.Equal(t, []int{<-calls, <-calls}, []int{0, 1})
+
+		_, err := Run(bulkhead, func() *Promise[string] {
+			assert.Fail(t, "This should never get called")
+			return nil
+		}).Await()
+		assert.ErrorIs(t, err, ErrBulkheadFull)
+
+		deferreds[0].Resolve("Someone famous")
+		assert.Equal(t, <-calls, 2)
+		name, err := promises[0].Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Someone famous")
+		assert.Equal(t, bulkhead.InFlight(), 2)
+		assert.Equal(t, bulkhead.QueueLength(), 0)
+
+		deferreds[1].Reject(errors.New("Famous people don't shake hands"))
+		deferreds[2].Resolve("Stunt Double")
+		_, err = promises[1].Await()
+		assert.EqualError(t, err, "Famous people don't shake hands")
+		name, _ = promises[2].Await()
+		assert.Equal(t, name, "Stunt Double")
+		assert.Equal(t, bulkhead.InFlight(), 0)
+	})
+	t.Run("Removes queued calls when their context is done", func(t *testing.T) {
+		bulkhead := NewBulkhead(1, 1)
+		running := NewDeferred[string]()
+		Run(bulkhead, running.Promise)
+		ctx, cancel := context.WithCancel(context.Background())
+		p := RunContext(ctx, bulkhead, func() *Promise[string] {
+			assert.Fail(t, "This should never get called")
+			return nil
+		})
+		assert.Equal(t, bulkhead.QueueLength(), 1)
+		cancel()
+		_, err := p.Await()
+		var cancelled *CancelledError
+		assert.ErrorAs(t, err, &cancelled)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, bulkhead.QueueLength(), 0)
+		running.Resolve("Someone famous")
+	})
+	t.Run("Cancels the running promise", func(t *testing.T) {
+		bulkhead := NewBulkhead(1, 1)
+		running := NewDeferred[string]()
+		p := Run(bulkhead, running.Promise)
+		queued := Run(bulkhead, func() *Promise[string] {
+			return Promisify[string]("Someone famous")
+		})
+		queued1 := Run(bulkhead, func() *Promise[string] {
+			return Promisify[string]("Stunt Double")
+		})
+		assert.True(t, p.Cancel(nil))
+		_, err := running.Promise().Await()
+		var cancelled *CancelledError
+		assert.ErrorAs(t, err, &cancelled)
+		name, _ := queued.Await()
+		assert.Equal(t, name, "Someone famous")
+		_, err = queued1.Await()
+		assert.ErrorIs(t, err, ErrBulkheadFull)
+		assert.Equal(t, bulkhead.InFlight(), 0)
+	})
+	t.Run("Keeps the place of a cancelled call until its function returns", func(t *testing.T) {
+		bulkhead := NewBulkhead(1, 10)
+		var running, peak atomic.Int32
+		unblock := make(chan struct{})
+		call := func() *Promise[string] {
+			return Promisify[string](func() (string, error) {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					current := peak.Load()
+					if n <= current || peak.CompareAndSwap(current, n) {
+						break
+					}
+				}
+				<-unblock
+				return "Someone famous", nil
+			})
+		}
+		p := Run(bulkhead, call)
+		queued := Run(bulkhead, call)
+		assert.Eventually(t, func() bool {
+			return running.Load() == 1
+		}, time.Second, time.Millisecond)
+		assert.True(t, p.Cancel(nil))
+		_, err := p.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+		assert.Never(t, func() bool {
+			return running.Load() > 1
+		}, 50*time.Millisecond, time.Millisecond)
+		assert.Equal(t, bulkhead.InFlight(), 1)
+		assert.Equal(t, bulkhead.QueueLength(), 1)
+
+		close(unblock)
+		name, err := queued.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, name, "Someone famous")
+		assert.Equal(t, peak.Load(), int32(1))
+		assert.Eventually(t, func() bool {
+			return bulkhead.InFlight() == 0
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("Rejects the promise when the factory panics", func(t *testing.T) {
+		bulkhead := NewBulkhead(1, 0)
+		_, err := Run(bulkhead, func() *Promise[string] {
+			panic("Famous people don't shake hands")
+		}).Await()
+		var panicErr *PanicError
+		assert.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, bulkhead.InFlight(), 0)
+	})
+}
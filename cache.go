@@ -0,0 +1,202 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// AsyncCacheOptions configures
+// an AsyncCache
+type AsyncCacheOptions struct {
+	// TTL is how long a loaded value is fresh,
+	// values never expire if zero
+	TTL time.Duration
+	// StaleWhileRevalidate is how long after its
+	// TTL a value is still served while it's
+	// loaded again in the background
+	StaleWhileRevalidate time.Duration
+	// CacheRejections caches the rejected loads
+	// for TTL too, by default the next Get
+	// loads the value again
+	CacheRejections bool
+}
+
+// CacheStats counts the
+// Gets of an AsyncCache
+type CacheStats struct {
+	// Hits are the Gets served from the cache,
+	// including the ones that joined a load
+	// in flight
+	Hits uint64
+	// Misses are the Gets that loaded the value
+	Misses uint64
+	// Stale are the hits that were
+	// served a stale value
+	Stale uint64
+}
+
+// AsyncCache caches the promises of a loader by
+// key, the concurrent Gets of a key that isn't
+// cached share one load.
+// It's safe to use from any go routine
+type AsyncCache[K comparable, V any] struct {
+	loader  func(key K) *Promise[V]
+	options AsyncCacheOptions
+	mutex   sync.Mutex
+	entries map[K]*cacheEntry[V]
+	stats   CacheStats
+}
+
+// cacheEntry is the load
+// of a key
+type cacheEntry[V any] struct {
+	deferred *Deferred[V]
+	// waitlist settles the
+	// promises of the Gets
+	waitlist waitlist[V]
+	// loadedAt is when the promise
+	// settled, zero while it's loading
+	loadedAt time.Time
+	rejected bool
+	// refreshing is true while a stale
+	// value is loaded again
+	refreshing bool
+}
+
+// NewAsyncCache
+// Creates an empty AsyncCache that
+// loads the values with loader
+func NewAsyncCache[K comparable, V any](loader func(key K) *Promise[V], options AsyncCacheOptions) *AsyncCache[K, V] {
+	return &AsyncCache[K, V]{
+		loader:  loader,
+		options: options,
+		entries: make(map[K]*cacheEntry[V]),
+	}
+}
+
+// Get
+// returns a promise that settles like the cached
+// promise of key, loading it on a miss. A stale
+// value is served while it's loaded again in the
+// background. Every caller gets its own promise
+// so cancelling it doesn't cancel the load
+func (cache *AsyncCache[K, V]) Get(key K) *Promise[V] {
+	now := currentClock().Now()
+	cache.mutex.Lock()
+	entry, ok := cache.entries[key]
+	load, refresh := false, false
+	switch {
+	case ok && cache.fresh(entry, now):
+		cache.stats.Hits++
+	case ok && cache.stale(entry, now):
+		cache.stats.Hits++
+		cache.stats.Stale++
+		refresh = !entry.refreshing
+		entry.refreshing = true
+	default:
+		cache.stats.Misses++
+		entry = &cacheEntry[V]{deferred: NewDeferred[V]()}
+		cache.entries[key] = entry
+		load = true
+	}
+	cache.mutex.Unlock()
+	if load {
+		cache.load(key, entry)
+	}
+	if refresh {
+		cache.refresh(key, entry)
+	}
+	return entry.waitlist.wait()
+}
+
+// Delete
+// removes key from the cache,
+// the next Get loads it again
+func (cache *AsyncCache[K, V]) Delete(key K) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	delete(cache.entries, key)
+}
+
+// Len
+// returns the number of
+// keys in the cache
+func (cache *AsyncCache[K, V]) Len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return len(cache.entries)
+}
+
+// Stats
+// returns the counts of the Gets
+func (cache *AsyncCache[K, V]) Stats() CacheStats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.stats
+}
+
+// fresh
+// returns true if the entry is loading or was
+// loaded less than TTL ago, the caller
+// holds the mutex
+func (cache *AsyncCache[K, V]) fresh(entry *cacheEntry[V], now time.Time) bool {
+	return entry.loadedAt.IsZero() || cache.options.TTL <= 0 || now.Sub(entry.loadedAt) < cache.options.TTL
+}
+
+// stale
+// returns true if the entry's value expired
+// but can still be served while it's loaded
+// again, the caller holds the mutex
+func (cache *AsyncCache[K, V]) stale(entry *cacheEntry[V], now time.Time) bool {
+	return !entry.rejected && now.Sub(entry.loadedAt) < cache.options.TTL+cache.options.StaleWhileRevalidate
+}
+
+// settle
+// settles the entry like the promise of the
+// loader, calls done with its error once it's
+// settled and then settles the Gets
+func (cache *AsyncCache[K, V]) settle(key K, entry *cacheEntry[V], done func(err error)) {
+	tap(entry.deferred.promise, func(obj V, err error) {
+		done(err)
+		entry.waitlist.settle(obj, err)
+	})
+	entry.deferred.follow(func() *Promise[V] {
+		return cache.loader(key)
+	}, func() {})
+}
+
+// load
+// loads the entry of a miss and removes
+// it if it's rejected, unless rejections
+// are cached
+func (cache *AsyncCache[K, V]) load(key K, entry *cacheEntry[V]) {
+	cache.settle(key, entry, func(err error) {
+		cache.mutex.Lock()
+		defer cache.mutex.Unlock()
+		if err != nil && !cache.options.CacheRejections {
+			if cache.entries[key] == entry {
+				delete(cache.entries, key)
+			}
+			return
+		}
+		entry.loadedAt = currentClock().Now()
+		entry.rejected = err != nil
+	})
+}
+
+// refresh
+// loads a stale entry again and replaces
+// it once the new value is loaded
+func (cache *AsyncCache[K, V]) refresh(key K, stale *cacheEntry[V]) {
+	entry := &cacheEntry[V]{deferred: NewDeferred[V]()}
+	cache.settle(key, entry, func(err error) {
+		cache.mutex.Lock()
+		defer cache.mutex.Unlock()
+		stale.refreshing = false
+		if err != nil || cache.entries[key] != stale {
+			return
+		}
+		entry.loadedAt = currentClock().Now()
+		cache.entries[key] = entry
+	})
+}
@@ -0,0 +1,121 @@
+package promise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncCache(t *testing.T) {
+	t.Run("Shares the load of a key", func(t *testing.T) {
+		deferred := NewDeferred[string]()
+		loads := atomic.Int32{}
+		cache := NewAsyncCache(func(key string) *Promise[string] {
+			loads.Add(1)
+			return deferred.Promise()
+		}, AsyncCacheOptions{})
+		first := cache.Get("famous")
+		second := cache.Get("famous")
+		deferred.Resolve("Someone famous")
+		for _, p := range []*Promise[string]{first, second, cache.Get("famous")} {
+			name, err := p.Await()
+			assert.Nil(t, err)
+			assert.Equal(t, name, "Someone famous")
+		}
+		assert.Equal(t, loads.Load(), int32(1))
+		assert.Equal(t, cache.Stats(), CacheStats{Hits: 2, Misses: 1})
+	})
+	t.Run("Loads the value again after the TTL", func(t *testing.T) {
+		clock := useTestClock(t)
+		loads := atomic.Int32{}
+		cache := NewAsyncCache(func(key string) *Promise[int32] {
+			return Promisify[int32](loads.Add(1))
+		}, AsyncCacheOptions{TTL: time.Minute})
+		first, _ := cache.Get("famous").Await()
+		clock.Advance(time.Minute - time.Second)
+		second, _ := cache.Get("famous").Await()
+		clock.Advance(time.Second)
+		third, _ := cache.Get("famous").Await()
+		assert.Equal(t, []int32{first, second, third}, []int32{1, 1, 2})
+		assert.Equal(t, cache.Stats(), CacheStats{Hits: 1, Misses: 2})
+	})
+	t.Run("Serves the stale value while it's loaded again", func(t *testing.T) {
+		clock := useTestClock(t)
+		deferreds := []*Deferred[string]{NewDeferred[string](), NewDeferred[string]()}
+		loads := atomic.Int32{}
+		cache := NewAsyncCache(func(key string) *Promise[string] {
+			return deferreds[loads.Add(1)-1].Promise()
+		}, AsyncCacheOptions{TTL: time.Minute, StaleWhileRevalidate: time.Minute})
+		deferreds[0].Resolve("Someone famous")
+		name, _ := cache.Get("famous").Await()
+		assert.Equal(t, name, "Someone famous")
+
+		clock.Advance(90 * time.Second)
+		name, _ = cache.Get("famous").Await()
+		assert.Equal(t, name, "Someone famous")
+		name, _ = cache.Get("famous").Await()
+		assert.Equal(t, name, "Someone famous")
+		assert.Equal(t, loads.Load(), int32(2))
+
+		deferreds[1].Resolve("Stunt Double")
+		assert.Eventually(t, func() bool {
+			name, _ := cache.Get("famous").Await()
+			return name == "Stunt Double"
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, loads.Load(), int32(2))
+	})
+	t.Run("Doesn't cache rejections by default", func(t *testing.T) {
+		loads := atomic.Int32{}
+		cache := NewAsyncCache(func(key string) *Promise[string] {
+			loads.Add(1)
+			return rejected[string](errors.New("Famous people don't shake hands"))
+		}, AsyncCacheOptions{})
+		_, err := cache.Get("famous").Await()
+		assert.EqualError(t, err, "Famous people don't shake hands")
+		assert.Eventually(t, func() bool { return cache.Len() == 0 }, time.Second, time.Millisecond)
+		cache.Get("famous").Await()
+		assert.Equal(t, loads.Load(), int32(2))
+	})
+	t.Run("Caches rejections when asked to", func(t *testing.T) {
+		loads := atomic.Int32{}
+		cache := NewAsyncCache(func(key string) *Promise[string] {
+			loads.Add(1)
+			return rejected[string](errors.New("Famous people don't shake hands"))
+		}, AsyncCacheOptions{CacheRejections: true})
+		cache.Get("famous").Await()
+		_, err := cache.Get("famous").Await()
+		assert.EqualError(t, err, "Famous people don't shake hands")
+		assert.Equal(t, loads.Load(), int32(1))
+	})
+	t.Run("Doesn't cancel the load when a caller cancels", func(t *testing.T) {
+		deferred := NewDeferred[string]()
+		cache := NewAsyncCache(func(key string) *Promise[string] {
+			return deferred.Promise()
+		}, AsyncCacheOptions{})
+		p := cache.Get("famous")
+		assert.True(t, p.Cancel(nil))
+		deferred.Resolve("Someone famous")
+		name, err := cache.Get("famous").Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Doesn't derive a promise from the cached one per Get", func(t *testing.T) {
+		deferred := NewDeferred[string]()
+		cache := NewAsyncCache(func(key string) *Promise[string] {
+			return deferred.Promise()
+		}, AsyncCacheOptions{})
+		waiting := cache.Get("famous")
+		deferred.Resolve("Someone famous")
+		waiting.Await()
+		for i := 0; i < 100; i++ {
+			cache.Get("famous").Await()
+		}
+		entry := cache.entries["famous"]
+		entry.deferred.promise.cancellation.mutex.Lock()
+		defer entry.deferred.promise.cancellation.mutex.Unlock()
+		assert.Equal(t, len(entry.deferred.promise.cancellation.children), 1)
+	})
+}
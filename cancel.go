@@ -0,0 +1,411 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+	"weak"
+)
+
+// contextType is the reflect type of context.Context
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// CancelledError is the error a promise
+// is rejected with when it's cancelled
+type CancelledError struct {
+	// Reason the promise was cancelled for
+	Reason error
+}
+
+// canceller is implemented by promises of
+// any type so a promise can cancel the
+// promises derived from it, and be cancelled
+// by them
+type canceller interface {
+	cancel(err *CancelledError) bool
+	childCancelled(err *CancelledError)
+	cancelledWith() *CancelledError
+}
+
+// cancellation stores the cancellation
+// state of a promise
+type cancellation struct {
+	mutex sync.Mutex
+	// err is set once the promise is cancelled
+	err *CancelledError
+	// started is true once the promise's
+	// step started running
+	started bool
+	// startedAt is when the step started
+	startedAt time.Time
+	// hooks that run when the promise
+	// is cancelled
+	hooks []func()
+	// children are weak references to the
+	// promises derived from the promise, so
+	// the ones nobody uses can be collected
+	children []func() canceller
+	// derived is the number of promises
+	// that were derived from the promise
+	derived int
+	// parent is the promise the promise
+	// was derived from
+	parent canceller
+	// upstream is true if the promise is
+	// cancelled once all of its children are,
+	// for the chains of context-aware sources
+	upstream bool
+	// pinned is true once the promise has a
+	// subscriber that isn't a derived promise,
+	// so it's never cancelled by its children
+	pinned bool
+	// rejected is true once the cancelled
+	// promise was rejected
+	rejected bool
+	// finished is true once the promise's step
+	// finished running, even if the promise was
+	// cancelled before
+	finished bool
+	// finishHooks run once the
+	// promise's step finished
+	finishHooks []func()
+	// external is true for the promises of a
+	// Deferred, their step finishes when
+	// they're settled
+	external bool
+	// done is closed once the cancelled
+	// promise was rejected
+	done chan struct{}
+}
+
+// Error
+// returns the error message
+func (err *CancelledError) Error() string {
+	if err.Reason == nil {
+		return "Promise was cancelled"
+	}
+	return fmt.Sprintf("Promise was cancelled: %v", err.Reason)
+}
+
+// Unwrap
+// returns the reason the promise was
+// cancelled for
+func (err *CancelledError) Unwrap() error {
+	return err.Reason
+}
+
+// weakly
+// returns a weak reference to promise that
+// returns nil once it was collected
+func weakly[T any](promise *Promise[T]) func() canceller {
+	pointer := weak.Make(promise)
+	return func() canceller {
+		if promise := pointer.Value(); promise != nil {
+			return promise
+		}
+		return nil
+	}
+}
+
+// derive
+// registers a promise derived from the
+// promise so it's cancelled with it
+func (c *cancellation) derive(child func() canceller) {
+	c.mutex.Lock()
+	err := c.err
+	if err == nil {
+		if len(c.children) == cap(c.children) {
+			c.prune()
+		}
+		c.children = append(c.children, child)
+		c.derived++
+	}
+	c.mutex.Unlock()
+	if err != nil {
+		child().cancel(err)
+	}
+}
+
+// prune
+// drops the children that were
+// collected, the caller holds the mutex
+func (c *cancellation) prune() {
+	kept := c.children[:0]
+	for _, child := range c.children {
+		if child() != nil {
+			kept = append(kept, child)
+		}
+	}
+	clear(c.children[len(kept):])
+	c.children = kept
+}
+
+// live
+// returns the children that weren't
+// collected, the caller holds the mutex
+func (c *cancellation) live() []canceller {
+	c.prune()
+	children := make([]canceller, 0, len(c.children))
+	for _, child := range c.children {
+		if child := child(); child != nil {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// pin
+// stops the children from
+// cancelling the promise
+func (c *cancellation) pin() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.pinned = true
+}
+
+// cancelChildren
+// cancels the promises derived from the promise
+func (c *cancellation) cancelChildren(err *CancelledError) {
+	c.mutex.Lock()
+	children := c.live()
+	c.mutex.Unlock()
+	for _, child := range children {
+		child.cancel(err)
+	}
+}
+
+// cancelled
+// returns a channel that is closed once
+// the cancelled promise was rejected
+func (c *cancellation) cancelled() <-chan struct{} {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.done == nil {
+		c.done = make(chan struct{})
+		if c.rejected {
+			close(c.done)
+		}
+	}
+	return c.done
+}
+
+// onFinish
+// runs f once the promise's step finished
+// running, right away if it did
+func (c *cancellation) onFinish(f func()) {
+	c.mutex.Lock()
+	if !c.finished {
+		c.finishHooks = append(c.finishHooks, f)
+		c.mutex.Unlock()
+		return
+	}
+	c.mutex.Unlock()
+	f()
+}
+
+// finish
+// marks the promise's step as finished
+// and runs the hooks waiting for it
+func (c *cancellation) finish() {
+	c.mutex.Lock()
+	if c.finished {
+		c.mutex.Unlock()
+		return
+	}
+	c.finished = true
+	hooks := c.finishHooks
+	c.finishHooks = nil
+	c.mutex.Unlock()
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// reject
+// marks the cancelled promise as rejected
+func (c *cancellation) reject() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.rejected = true
+	if c.done != nil {
+		close(c.done)
+	}
+}
+
+// begin
+// marks the promise's step as started.
+// Returns false and releases the queue if
+// the promise was cancelled before it
+// started, the promise is rejected already
+func (promise *Promise[T]) begin() bool {
+	now := currentClock().Now()
+	c := promise.cancellation
+	c.mutex.Lock()
+	err := c.err
+	c.started = err == nil
+	if c.started {
+		c.startedAt = now
+	}
+	c.mutex.Unlock()
+	if err != nil {
+		// cancel rejects the promise,
+		// storing the result here too makes
+		// sure it's stored before the next
+		// step reads it
+		var obj T
+		promise.store(obj, err)
+		promise.queue.release()
+		return false
+	}
+	promise.traceStart()
+	return true
+}
+
+// cancel
+// rejects the promise with err if it isn't
+// settled yet, runs its OnCancel hooks and
+// cancels the promises derived from it
+func (promise *Promise[T]) cancel(err *CancelledError) bool {
+	c := promise.cancellation
+	var obj T
+	c.mutex.Lock()
+	if c.err != nil {
+		c.mutex.Unlock()
+		return false
+	}
+	// a started promise is rejected and its
+	// step's result is dropped. A promise that
+	// didn't start is rejected without handing
+	// the queue over, setting err before
+	// unlocking keeps its step from running
+	started := c.started
+	if started {
+		c.mutex.Unlock()
+		if !promise.settle(obj, err) {
+			c.cancelChildren(err)
+			return false
+		}
+		c.mutex.Lock()
+	}
+	c.err = err
+	hooks := c.hooks
+	c.hooks = nil
+	c.mutex.Unlock()
+	if !started {
+		promise.store(obj, err)
+	}
+	c.reject()
+	for _, hook := range hooks {
+		hook()
+	}
+	c.cancelChildren(err)
+	if c.parent != nil {
+		c.parent.childCancelled(err)
+	}
+	return true
+}
+
+// childCancelled
+// cancels the promise of a context-aware
+// chain once all of the promises derived
+// from it were cancelled, unless it
+// has other subscribers
+func (promise *Promise[T]) childCancelled(err *CancelledError) {
+	c := promise.cancellation
+	c.mutex.Lock()
+	if !c.upstream || c.pinned || c.err != nil {
+		c.mutex.Unlock()
+		return
+	}
+	children := c.live()
+	derived := c.derived
+	c.mutex.Unlock()
+	for _, child := range children {
+		if child.cancelledWith() == nil {
+			return
+		}
+	}
+	// a promise derived in the meantime
+	// still uses the promise
+	c.mutex.Lock()
+	abandoned := c.derived == derived && !c.pinned
+	c.mutex.Unlock()
+	if abandoned {
+		promise.cancel(err)
+	}
+}
+
+// Cancel
+// rejects the promise with a *CancelledError
+// right away if it isn't settled yet, runs the
+// OnCancel hooks and cancels the promises that
+// were created from it using Then and Catch.
+// The step of a promise that didn't start is
+// skipped when its turn comes.
+// A running function's result is dropped and
+// the context of a function promisified with
+// PromisifyContext is cancelled. In a chain
+// created from PromisifyContext the promise a
+// promise was created from is cancelled too
+// once every promise created from it was,
+// unless it's subscribed to with the Then,
+// Catch or Finally methods, Exec or Await.
+// Returns false if the promise was already settled
+func (promise *Promise[T]) Cancel(reason error) bool {
+	return promise.cancel(&CancelledError{Reason: reason})
+}
+
+// OnCancel
+// registers a function that runs when the
+// promise is cancelled.
+// Ideal for cleaning up resources
+func (promise *Promise[T]) OnCancel(hook func()) {
+	c := promise.cancellation
+	c.mutex.Lock()
+	if c.err == nil {
+		c.hooks = append(c.hooks, hook)
+		c.mutex.Unlock()
+		return
+	}
+	c.mutex.Unlock()
+	hook()
+}
+
+// cancelledWith
+// returns the error the promise was
+// cancelled with, nil if it wasn't
+func (promise *Promise[T]) cancelledWith() *CancelledError {
+	c := promise.cancellation
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.err
+}
+
+// PromisifyContext
+// Creates a promise from a function that takes
+// a context.Context as its first argument, like
+// Promisify. The function gets a context derived
+// from ctx that is cancelled when the promise is
+// cancelled
+func PromisifyContext[T any](ctx context.Context, fn any, args ...any) *Promise[T] {
+	if fn == nil || !isFunction(fn) {
+		panic(fmt.Sprintf("Promise can't run %T as it isn't a function", fn))
+	}
+	function := reflect.ValueOf(fn)
+	plan := planOf(function.Type())
+	if len(plan.in) == 0 || plan.in[0] != contextType {
+		panic("Promise function has to take a context.Context as its first argument")
+	}
+	promise := newPromise[T](ctx, stepContext)
+	promise.cancellation.upstream = true
+	ctx, cancel := context.WithCancel(promise.ctx)
+	promise.OnCancel(cancel)
+	values := plan.values(append([]any{ctx}, args...))
+	runFunc(promise, func(function reflect.Value, args ...reflect.Value) (T, error) {
+		defer cancel()
+		return funcRunner[T](function, args...)
+	}, function, values...)
+	return promise
+}
@@ -0,0 +1,251 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromiseCancel(t *testing.T) {
+	t.Run("Rejects a running promise with a CancelledError", func(t *testing.T) {
+		unblock := make(chan struct{})
+		defer close(unblock)
+		started := make(chan struct{})
+		p := Promisify[testMessage](func() (testMessage, error) {
+			close(started)
+			<-unblock
+			return testMessage{Name: "Someone famous"}, nil
+		})
+		<-started
+		reason := fmt.Errorf("Famous people are too busy")
+		assert.True(t, p.Cancel(reason))
+		_, err := p.Await()
+		var cancelled *CancelledError
+		assert.ErrorAs(t, err, &cancelled)
+		assert.ErrorIs(t, err, reason)
+		assert.False(t, p.Cancel(reason))
+	})
+	t.Run("Cancels the derived promises", func(t *testing.T) {
+		unblock := make(chan struct{})
+		defer close(unblock)
+		p := Promisify[string](func() (string, error) {
+			<-unblock
+			return "Someone famous", nil
+		})
+		p1 := Then(p, func(name string) (int, error) {
+			assert.Fail(t, "This should never get called")
+			return len(name), nil
+		})
+		p2 := Catch(p1, func(err error) (string, error) {
+			assert.Fail(t, "This should never get called")
+			return err.Error(), nil
+		})
+		p.Cancel(nil)
+		_, err := p1.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+		_, err = p2.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+	})
+	t.Run("Cancels promises derived from a cancelled promise", func(t *testing.T) {
+		d := NewDeferred[string]()
+		d.Promise().Cancel(nil)
+		assert.True(t, d.Settled())
+		assert.False(t, d.Resolve("Someone famous"))
+		_, err := Then(d.Promise(), func(name string) (int, error) {
+			return len(name), nil
+		}).Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+	})
+	t.Run("Rejects a promise that didn't start right away", func(t *testing.T) {
+		d := NewDeferred[string]()
+		p := Then(d.Promise(), func(name string) (int, error) {
+			assert.Fail(t, "This should never get called")
+			return len(name), nil
+		})
+		p1 := Then(p, func(n int) (int, error) {
+			assert.Fail(t, "This should never get called")
+			return n, nil
+		})
+		assert.True(t, p.Cancel(nil))
+		assert.Equal(t, p.State(), Rejected)
+		_, err := p.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+		_, err = p1.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+
+		d.Resolve("Someone famous")
+		name, err := d.Promise().Await()
+		assert.NoError(t, err)
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Doesn't keep the derived promises nobody uses", func(t *testing.T) {
+		p := Promisify[string]("Someone famous")
+		for i := 0; i < 100; i++ {
+			Then(p, func(name string) (int, error) {
+				return len(name), nil
+			}).Await()
+		}
+		runtime.GC()
+		p.cancellation.mutex.Lock()
+		defer p.cancellation.mutex.Unlock()
+		assert.Empty(t, p.cancellation.live())
+	})
+	t.Run("Returns true only if the promise is rejected with a CancelledError", func(t *testing.T) {
+		for i := 0; i < 200; i++ {
+			p := Promisify[int](func() (int, error) {
+				return i, nil
+			})
+			cancelled := p.Cancel(nil)
+			_, err := p.Await()
+			if cancelled {
+				assert.ErrorAs(t, err, new(*CancelledError))
+			} else {
+				assert.NoError(t, err)
+			}
+		}
+	})
+	t.Run("Doesn't cancel a settled promise", func(t *testing.T) {
+		p := Promisify[string]("Someone famous")
+		name, err := p.Await()
+		assert.NoError(t, err)
+		assert.False(t, p.Cancel(nil))
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Runs the OnCancel hooks once", func(t *testing.T) {
+		calls := atomic.Int32{}
+		d := NewDeferred[string]()
+		d.Promise().OnCancel(func() {
+			calls.Add(1)
+		})
+		d.Promise().Cancel(nil)
+		d.Promise().Cancel(nil)
+		assert.Equal(t, calls.Load(), int32(1))
+		d.Promise().OnCancel(func() {
+			calls.Add(1)
+		})
+		assert.Equal(t, calls.Load(), int32(2))
+	})
+	t.Run("Doesn't treat cancellation as an unhandled error", func(t *testing.T) {
+		d := NewDeferred[string]()
+		d.Promise().Cancel(nil)
+		assert.NotPanics(t, d.Promise().Exec)
+	})
+}
+
+func TestPromisifyContext(t *testing.T) {
+	t.Run("Passes the context to the function", func(t *testing.T) {
+		type key struct{}
+		ctx := context.WithValue(context.Background(), key{}, "Someone famous")
+		p := PromisifyContext[string](ctx, func(ctx context.Context, subject string) (string, error) {
+			return ctx.Value(key{}).(string) + ": " + subject, nil
+		}, "Hi famous person")
+		msg, err := p.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, msg, "Someone famous: Hi famous person")
+	})
+	t.Run("Cancels the function's context when cancelled", func(t *testing.T) {
+		done := make(chan error, 1)
+		started := make(chan struct{})
+		p := PromisifyContext[string](context.Background(), func(ctx context.Context) (string, error) {
+			close(started)
+			<-ctx.Done()
+			done <- ctx.Err()
+			return "", ctx.Err()
+		})
+		<-started
+		p.Cancel(nil)
+		assert.ErrorIs(t, <-done, context.Canceled)
+		_, err := p.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+	})
+	t.Run("Cancels the source's context when the derived promises are cancelled", func(t *testing.T) {
+		done := make(chan error, 1)
+		started := make(chan struct{})
+		p := PromisifyContext[string](context.Background(), func(ctx context.Context) (string, error) {
+			close(started)
+			<-ctx.Done()
+			done <- ctx.Err()
+			return "", ctx.Err()
+		})
+		p1 := Then(p, func(name string) (int, error) {
+			return len(name), nil
+		})
+		p2 := Then(p1, func(n int) (int, error) {
+			return n * 2, nil
+		})
+		<-started
+		assert.True(t, p2.Cancel(nil))
+		assert.ErrorIs(t, <-done, context.Canceled)
+		_, err := p.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+		_, err = p1.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+	})
+	t.Run("Doesn't cancel a source that is still used", func(t *testing.T) {
+		unblock := make(chan struct{})
+		started := make(chan struct{})
+		p := PromisifyContext[string](context.Background(), func(ctx context.Context) (string, error) {
+			close(started)
+			<-unblock
+			return "Someone famous", ctx.Err()
+		})
+		p1 := Then(p, func(name string) (int, error) {
+			return len(name), nil
+		})
+		p2 := Then(p, func(name string) (string, error) {
+			return name, nil
+		})
+		<-started
+		assert.True(t, p1.Cancel(nil))
+		close(unblock)
+		name, err := p2.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, name, "Someone famous")
+
+		unblock = make(chan struct{})
+		started = make(chan struct{})
+		p = PromisifyContext[string](context.Background(), func(ctx context.Context) (string, error) {
+			close(started)
+			<-unblock
+			return "Someone famous", ctx.Err()
+		})
+		// the Then method blocks until p ran
+		go p.Then(func(string) {})
+		assert.Eventually(t, func() bool {
+			p.cancellation.mutex.Lock()
+			defer p.cancellation.mutex.Unlock()
+			return p.cancellation.pinned
+		}, time.Second, time.Millisecond)
+		p1 = Then(p, func(name string) (int, error) {
+			return len(name), nil
+		})
+		<-started
+		assert.True(t, p1.Cancel(nil))
+		close(unblock)
+		name, err = p.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Rejects when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		p := PromisifyContext[string](ctx, func(ctx context.Context) (string, error) {
+			return "", ctx.Err()
+		})
+		_, err := p.Await()
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+	t.Run("Panics if the function doesn't take a context", func(t *testing.T) {
+		assert.Panics(t, func() {
+			PromisifyContext[string](context.Background(), func() (string, error) {
+				return "", nil
+			})
+		})
+	})
+}
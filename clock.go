@@ -0,0 +1,61 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of the
+// promises that wait, it can be replaced
+// with SetClock so tests can advance time
+// instead of sleeping
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// AfterFunc calls f after d has passed and
+	// returns a function that stops the call,
+	// stop returns false if f was already called
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+// realClock is the Clock backed by
+// the time package
+type realClock struct{}
+
+var (
+	clockMutex sync.RWMutex
+	// clock is the Clock used by the package
+	clock Clock = realClock{}
+)
+
+// Now
+// returns the current time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc
+// calls f in its own go routine after d
+func (realClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
+
+// SetClock
+// replaces the Clock used by the package
+// and returns the previous one so it can
+// be restored
+func SetClock(c Clock) Clock {
+	clockMutex.Lock()
+	defer clockMutex.Unlock()
+	previous := clock
+	clock = c
+	return previous
+}
+
+// currentClock
+// returns the Clock used by the package
+func currentClock() Clock {
+	clockMutex.RLock()
+	defer clockMutex.RUnlock()
+	return clock
+}
@@ -0,0 +1,140 @@
+package promise
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// DebugEnv is the environment variable that
+// enables debug mode when it's set to true
+const DebugEnv = "PROMISE_DEBUG"
+
+// debugMode is true while the creation
+// stacks of the promises are captured
+var debugMode atomic.Bool
+
+func init() {
+	enabled, _ := strconv.ParseBool(os.Getenv(DebugEnv))
+	debugMode.Store(enabled)
+}
+
+// SetDebug
+// starts or stops capturing the call stacks
+// that create promises and attach Then and
+// Catch subscribers to them, and returns
+// whether they were captured before.
+// The stacks are included in unhandled
+// rejection reports and in PanicErrors.
+// Capturing stacks is slow so it's
+// meant for debugging
+func SetDebug(enabled bool) bool {
+	return debugMode.Swap(enabled)
+}
+
+// origin is the call stack that created a
+// promise and the origin of the promise it
+// was created from
+type origin struct {
+	id     uint64
+	stack  string
+	parent *origin
+}
+
+// newOrigin
+// captures the origin of the promise with
+// id in debug mode, nil otherwise
+func newOrigin(id uint64, parent *origin) *origin {
+	if !debugMode.Load() {
+		return nil
+	}
+	return &origin{
+		id:     id,
+		stack:  callerStack(),
+		parent: parent,
+	}
+}
+
+// String
+// describes where the promise and the
+// promises it was created from were created
+func (o *origin) String() string {
+	if o == nil {
+		return ""
+	}
+	report := strings.Builder{}
+	fmt.Fprintf(&report, "Promise #%d was created at:\n%s", o.id, o.stack)
+	for parent := o.parent; parent != nil; parent = parent.parent {
+		fmt.Fprintf(&report, "from promise #%d created at:\n%s", parent.id, parent.stack)
+	}
+	return report.String()
+}
+
+// attachment
+// captures the call stack that attaches
+// a subscriber in debug mode
+func attachment() string {
+	if !debugMode.Load() {
+		return ""
+	}
+	return callerStack()
+}
+
+// PanicError is the error a promise is
+// rejected with when its step panics, or that
+// replaces its error when a Then or Catch
+// subscriber panics
+type PanicError struct {
+	// Value that was recovered
+	Value any
+	// Stack of the go routine that panicked
+	Stack string
+	// Origin describes where the promise and the
+	// promises it was created from were created,
+	// only in debug mode
+	Origin string
+	// Attached is the call stack that attached
+	// the subscriber that panicked, only in
+	// debug mode
+	Attached string
+}
+
+// Error
+// returns the error message, with the
+// origin of the promise in debug mode
+func (err *PanicError) Error() string {
+	msg := fmt.Sprintf("Promise entered an unhealth state due to panic:\n %v", err.Value)
+	if err.Attached != "" {
+		msg += "\nThe subscriber was attached at:\n" + err.Attached
+	}
+	if err.Origin != "" {
+		msg += "\n" + err.Origin
+	}
+	return msg
+}
+
+// Unwrap
+// returns the recovered value
+// if it's an error
+func (err *PanicError) Unwrap() error {
+	if e, ok := err.Value.(error); ok {
+		return e
+	}
+	return nil
+}
+
+// panicError
+// creates the PanicError of a panic of the
+// promise's step or of the subscriber
+// attached at attached
+func (promise *Promise[T]) panicError(r any, attached string) *PanicError {
+	return &PanicError{
+		Value:    r,
+		Stack:    string(debug.Stack()),
+		Origin:   promise.origin.String(),
+		Attached: attached,
+	}
+}
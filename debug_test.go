@@ -0,0 +1,78 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func useDebug(t *testing.T) {
+	previous := SetDebug(true)
+	t.Cleanup(func() {
+		SetDebug(previous)
+	})
+}
+
+func TestDebug(t *testing.T) {
+	t.Run("Reports where an unhandled promise was created", func(t *testing.T) {
+		useDebug(t)
+		p := Promisify[testMessage](func() (testMessage, error) {
+			return testMessage{}, errors.New("Famous people don't shake hands")
+		})
+		p1 := Then(p, func(tm testMessage) (string, error) {
+			return tm.Name, nil
+		})
+		defer func() {
+			r := recover()
+			assert.NotNil(t, r)
+			report := fmt.Sprint(r)
+			assert.Contains(t, report, "Promise execution has an unhandled error of Famous people don't shake hands")
+			assert.Contains(t, report, fmt.Sprintf("Promise #%d was created at:\n", p1.ID()))
+			assert.Contains(t, report, fmt.Sprintf("from promise #%d created at:\n", p.ID()))
+			assert.Contains(t, report, "TestDebug")
+			assert.NotContains(t, report, "fromPromise")
+		}()
+		p1.Exec()
+	})
+	t.Run("Includes the stacks in panic errors", func(t *testing.T) {
+		useDebug(t)
+		p := Promisify[string](func() (string, error) {
+			panic("Famous people don't shake hands")
+		})
+		_, err := p.Await()
+		var panicErr *PanicError
+		assert.True(t, errors.As(err, &panicErr))
+		assert.Equal(t, panicErr.Value, "Famous people don't shake hands")
+		assert.Contains(t, panicErr.Stack, "debug_test.go")
+		assert.Contains(t, panicErr.Origin, fmt.Sprintf("Promise #%d was created at:\n", p.ID()))
+		assert.Contains(t, panicErr.Origin, "TestDebug")
+		assert.Empty(t, panicErr.Attached)
+		assert.Contains(t, err.Error(), panicErr.Origin)
+
+		p1 := Promisify[string]("Someone famous")
+		p1.Then(func(string) {
+			panic("Stunt Double")
+		})
+		p1.Catch(func(err error) {
+			assert.True(t, errors.As(err, &panicErr))
+		})
+		p1.Exec()
+		assert.Equal(t, panicErr.Value, "Stunt Double")
+		assert.Contains(t, panicErr.Attached, "TestDebug")
+	})
+	t.Run("Doesn't capture stacks when disabled", func(t *testing.T) {
+		SetDebug(false)
+		p := Promisify[string](func() (string, error) {
+			panic(errors.New("Famous people don't shake hands"))
+		})
+		_, err := p.Await()
+		var panicErr *PanicError
+		assert.True(t, errors.As(err, &panicErr))
+		assert.Empty(t, panicErr.Origin)
+		assert.NotEmpty(t, panicErr.Stack)
+		assert.EqualError(t, errors.Unwrap(err), "Famous people don't shake hands")
+		assert.Equal(t, err.Error(), "Promise entered an unhealth state due to panic:\n Famous people don't shake hands")
+	})
+}
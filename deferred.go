@@ -0,0 +1,164 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Deferred is a promise that is settled
+// by someone else by calling Resolve or
+// Reject. Ideal for bridging callbacks
+// and events to promises.
+// It's safe to use from any go routine
+type Deferred[T any] struct {
+	// promise that is settled by the deferred
+	promise *Promise[T]
+}
+
+// NewDeferred
+// Creates a new Deferred with a pending promise
+func NewDeferred[T any]() *Deferred[T] {
+	promise := newPromise[T](context.Background(), stepDeferred)
+	promise.cancellation.external = true
+	promise.wg.Add(1)
+	promise.queue.hold()
+	promise.begin()
+	return &Deferred[T]{
+		promise: promise,
+	}
+}
+
+// rejected
+// Creates a promise that is
+// rejected with err
+func rejected[T any](err error) *Promise[T] {
+	deferred := NewDeferred[T]()
+	deferred.Reject(err)
+	return deferred.Promise()
+}
+
+// waitlist settles the promises of the callers
+// waiting for a shared result from the stored
+// result, so a long-lived shared promise doesn't
+// get a derived promise for each of them
+type waitlist[T any] struct {
+	mutex sync.Mutex
+	// waiting are the promises of
+	// the callers until it's settled
+	waiting []*Promise[T]
+	settled bool
+	obj     T
+	err     error
+}
+
+// wait
+// returns a promise that settles
+// with the result
+func (list *waitlist[T]) wait() *Promise[T] {
+	promise := NewDeferred[T]().promise
+	list.mutex.Lock()
+	if !list.settled {
+		list.waiting = append(list.waiting, promise)
+		list.mutex.Unlock()
+		return promise
+	}
+	obj, err := list.obj, list.err
+	list.mutex.Unlock()
+	promise.settle(obj, err)
+	return promise
+}
+
+// settle
+// stores the result and settles the
+// promises of the waiting callers
+func (list *waitlist[T]) settle(obj T, err error) {
+	list.mutex.Lock()
+	if list.settled {
+		list.mutex.Unlock()
+		return
+	}
+	list.settled = true
+	list.obj, list.err = obj, err
+	waiting := list.waiting
+	list.waiting = nil
+	list.mutex.Unlock()
+	for _, promise := range waiting {
+		promise.settle(obj, err)
+	}
+}
+
+// callFactory
+// calls factory and returns its promise, or
+// a promise rejected with a PanicError if
+// factory panics
+func callFactory[T any](factory func() *Promise[T]) (promise *Promise[T]) {
+	defer func() {
+		if r := recover(); r != nil {
+			deferred := NewDeferred[T]()
+			deferred.Reject(deferred.promise.panicError(r, ""))
+			promise = deferred.promise
+		}
+	}()
+	return factory()
+}
+
+// follow
+// calls factory and settles the deferred like
+// the promise it creates, cancelling the
+// deferred's promise cancels that promise.
+// done is called once that promise settled
+func (deferred *Deferred[T]) follow(factory func() *Promise[T], done func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			done()
+			deferred.Reject(deferred.promise.panicError(r, ""))
+		}
+	}()
+	promise := factory()
+	deferred.promise.OnCancel(func() {
+		promise.cancel(deferred.promise.cancelledWith())
+	})
+	tap(promise, func(obj T, err error) {
+		done()
+		if err != nil {
+			deferred.Reject(err)
+		} else {
+			deferred.Resolve(obj)
+		}
+	})
+}
+
+// Promise
+// returns the promise that is settled
+// by the deferred
+func (deferred *Deferred[T]) Promise() *Promise[T] {
+	return deferred.promise
+}
+
+// Resolve
+// fulfills the promise with obj.
+// Returns false without doing anything if
+// the promise was already settled
+func (deferred *Deferred[T]) Resolve(obj T) bool {
+	return deferred.promise.settle(obj, nil)
+}
+
+// Reject
+// fails the promise with err.
+// Returns false without doing anything if
+// the promise was already settled
+func (deferred *Deferred[T]) Reject(err error) bool {
+	if err == nil {
+		err = fmt.Errorf("Promise was rejected with a nil error")
+	}
+	var obj T
+	return deferred.promise.settle(obj, err)
+}
+
+// Settled
+// returns true if the promise was settled
+// by Resolve, Reject or by cancelling it
+func (deferred *Deferred[T]) Settled() bool {
+	return deferred.promise.settled.Load()
+}
@@ -0,0 +1,65 @@
+package promise
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeferred(t *testing.T) {
+	t.Run("Resolves the promise after subscribing to it", func(t *testing.T) {
+		d := NewDeferred[testMessage]()
+		p := Then(d.Promise(), func(tm testMessage) (string, error) {
+			return tm.Name, nil
+		})
+		assert.False(t, d.Settled())
+		assert.True(t, d.Resolve(testMessage{
+			Name:    "Someone famous",
+			Subject: "Hi famous person",
+		}))
+		assert.True(t, d.Settled())
+		name, err := p.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Rejects the promise", func(t *testing.T) {
+		d := NewDeferred[testMessage]()
+		p := Catch(d.Promise(), func(err error) (string, error) {
+			return err.Error(), nil
+		})
+		assert.True(t, d.Reject(fmt.Errorf("Famous people don't shake hands")))
+		msg, err := p.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, msg, "Famous people don't shake hands")
+	})
+	t.Run("Rejects with an error when the error is nil", func(t *testing.T) {
+		d := NewDeferred[testMessage]()
+		d.Reject(nil)
+		_, err := d.Promise().Await()
+		assert.Error(t, err)
+	})
+	t.Run("Only settles once", func(t *testing.T) {
+		d := NewDeferred[int]()
+		wg := sync.WaitGroup{}
+		settled := atomic.Int32{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if i%2 == 0 && d.Resolve(i) {
+					settled.Add(1)
+				}
+				if i%2 == 1 && d.Reject(fmt.Errorf("error %d", i)) {
+					settled.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, settled.Load(), int32(1))
+		assert.False(t, d.Resolve(100))
+		assert.False(t, d.Reject(fmt.Errorf("late error")))
+	})
+}
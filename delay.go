@@ -0,0 +1,26 @@
+package promise
+
+import "time"
+
+// Delay
+// Creates a promise that is fulfilled
+// with obj once d has passed.
+// Cancelling the promise stops the wait
+func Delay[T any](d time.Duration, obj T) *Promise[T] {
+	deferred := NewDeferred[T]()
+	stop := currentClock().AfterFunc(d, func() {
+		deferred.Resolve(obj)
+	})
+	deferred.Promise().OnCancel(func() {
+		stop()
+	})
+	return deferred.Promise()
+}
+
+// Sleep
+// Creates a promise that is fulfilled
+// once d has passed.
+// Ideal for waiting between retries
+func Sleep(d time.Duration) *Promise[struct{}] {
+	return Delay(d, struct{}{})
+}
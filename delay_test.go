@@ -0,0 +1,116 @@
+package promise
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testClock is a Clock that only
+// moves when it's advanced
+type testClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*testTimer
+}
+
+type testTimer struct {
+	at time.Time
+	f  func()
+}
+
+func (clock *testClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+func (clock *testClock) AfterFunc(d time.Duration, f func()) func() bool {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	timer := &testTimer{at: clock.now.Add(d), f: f}
+	clock.timers = append(clock.timers, timer)
+	return func() bool {
+		clock.mutex.Lock()
+		defer clock.mutex.Unlock()
+		for i, t := range clock.timers {
+			if t == timer {
+				clock.timers = append(clock.timers[:i], clock.timers[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (clock *testClock) Advance(d time.Duration) {
+	clock.mutex.Lock()
+	clock.now = clock.now.Add(d)
+	due := make([]*testTimer, 0)
+	pending := make([]*testTimer, 0)
+	for _, timer := range clock.timers {
+		if timer.at.After(clock.now) {
+			pending = append(pending, timer)
+		} else {
+			due = append(due, timer)
+		}
+	}
+	clock.timers = pending
+	clock.mutex.Unlock()
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].at.Before(due[j].at)
+	})
+	for _, timer := range due {
+		timer.f()
+	}
+}
+
+func useTestClock(t *testing.T) *testClock {
+	clock := &testClock{now: time.Unix(0, 0)}
+	previous := SetClock(clock)
+	t.Cleanup(func() {
+		SetClock(previous)
+	})
+	return clock
+}
+
+func TestDelay(t *testing.T) {
+	t.Run("Fulfills the promise after the delay", func(t *testing.T) {
+		clock := useTestClock(t)
+		p := Delay(time.Second, "Someone famous")
+		clock.Advance(time.Second - time.Millisecond)
+		assert.Equal(t, p.State(), Pending)
+		clock.Advance(time.Millisecond)
+		name, err := p.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Chains delays", func(t *testing.T) {
+		clock := useTestClock(t)
+		p := Then(Sleep(time.Second), func(struct{}) (int, error) {
+			return 1, nil
+		})
+		clock.Advance(time.Second)
+		count, err := p.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, count, 1)
+	})
+	t.Run("Stops waiting when cancelled", func(t *testing.T) {
+		clock := useTestClock(t)
+		p := Sleep(time.Minute)
+		assert.Len(t, clock.timers, 1)
+		p.Cancel(nil)
+		assert.Len(t, clock.timers, 0)
+		_, err := p.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+	})
+	t.Run("Uses the real clock by default", func(t *testing.T) {
+		start := time.Now()
+		_, err := Sleep(10 * time.Millisecond).Await()
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,51 @@
+package promise
+
+import "sync"
+
+// Executor runs the steps of promises,
+// the function that creates a promise
+// and the functions given to Then,
+// Catch and Finally
+type Executor interface {
+	// Execute runs the task without
+	// blocking the caller
+	Execute(task func())
+}
+
+// goExecutor is the Executor that
+// runs every task in its own go routine
+type goExecutor struct{}
+
+var (
+	executorMutex sync.RWMutex
+	// executor is the Executor used by the package
+	executor Executor = goExecutor{}
+)
+
+// Execute
+// runs the task in a go routine
+func (goExecutor) Execute(task func()) {
+	go task()
+}
+
+// SetExecutor
+// replaces the Executor used by the promises
+// created from now on and returns the previous
+// one so it can be restored.
+// Promises created from a promise use the
+// Executor of the promise they're created from
+func SetExecutor(e Executor) Executor {
+	executorMutex.Lock()
+	defer executorMutex.Unlock()
+	previous := executor
+	executor = e
+	return previous
+}
+
+// currentExecutor
+// returns the Executor used by the package
+func currentExecutor() Executor {
+	executorMutex.RLock()
+	defer executorMutex.RUnlock()
+	return executor
+}
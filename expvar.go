@@ -0,0 +1,206 @@
+package promise
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the
+// buckets of the latency histograms created
+// from now on by ExpvarMetrics
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// ExpvarMetrics is a RenamingMetrics that publishes
+// the counts and latencies of the promises
+// with the expvar package, so they're served
+// by /debug/vars
+type ExpvarMetrics struct {
+	vars *expvar.Map
+	// mutex guards the creation of
+	// the metrics of a name
+	mutex sync.Mutex
+}
+
+// nameMetrics are the
+// metrics of a name
+type nameMetrics struct {
+	created   *expvar.Int
+	fulfilled *expvar.Int
+	rejected  *expvar.Int
+	cancelled *expvar.Int
+	panicked  *expvar.Int
+	latency   *histogram
+}
+
+// histogram counts the settle
+// latencies per bucket
+type histogram struct {
+	mutex sync.Mutex
+	// bounds are the upper bounds of the
+	// buckets, the last bucket has no bound
+	bounds []time.Duration
+	counts []int64
+	count  int64
+	sum    time.Duration
+}
+
+// NewExpvarMetrics
+// Creates an ExpvarMetrics that publishes the
+// metrics as the expvar variable named name,
+// set it with SetMetrics.
+// It panics if the variable already exists
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{vars: expvar.NewMap(name)}
+}
+
+// of
+// returns the metrics of name
+// and creates them if needed
+func (m *ExpvarMetrics) of(name string) *nameMetrics {
+	if vars, ok := m.vars.Get(name).(*expvar.Map); ok {
+		return metricsOf(vars)
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if vars, ok := m.vars.Get(name).(*expvar.Map); ok {
+		return metricsOf(vars)
+	}
+	vars := new(expvar.Map).Init()
+	vars.Set("created", new(expvar.Int))
+	vars.Set("fulfilled", new(expvar.Int))
+	vars.Set("rejected", new(expvar.Int))
+	vars.Set("cancelled", new(expvar.Int))
+	vars.Set("panicked", new(expvar.Int))
+	vars.Set("latency", &histogram{
+		bounds: append([]time.Duration(nil), LatencyBuckets...),
+		counts: make([]int64, len(LatencyBuckets)+1),
+	})
+	m.vars.Set(name, vars)
+	return metricsOf(vars)
+}
+
+// metricsOf
+// returns the metrics in vars
+func metricsOf(vars *expvar.Map) *nameMetrics {
+	return &nameMetrics{
+		created:   vars.Get("created").(*expvar.Int),
+		fulfilled: vars.Get("fulfilled").(*expvar.Int),
+		rejected:  vars.Get("rejected").(*expvar.Int),
+		cancelled: vars.Get("cancelled").(*expvar.Int),
+		panicked:  vars.Get("panicked").(*expvar.Int),
+		latency:   vars.Get("latency").(*histogram),
+	}
+}
+
+// Created
+// counts a created promise
+func (m *ExpvarMetrics) Created(name string) {
+	m.of(name).created.Add(1)
+}
+
+// Renamed
+// moves the count of a created
+// promise to its new name
+func (m *ExpvarMetrics) Renamed(from, to string) {
+	m.of(from).created.Add(-1)
+	m.of(to).created.Add(1)
+}
+
+// Fulfilled
+// counts a fulfilled promise
+// and observes its latency
+func (m *ExpvarMetrics) Fulfilled(name string, latency time.Duration) {
+	metrics := m.of(name)
+	metrics.fulfilled.Add(1)
+	metrics.latency.observe(latency)
+}
+
+// Rejected
+// counts a rejected promise
+// and observes its latency
+func (m *ExpvarMetrics) Rejected(name string, latency time.Duration) {
+	metrics := m.of(name)
+	metrics.rejected.Add(1)
+	metrics.latency.observe(latency)
+}
+
+// Cancelled
+// counts a cancelled promise
+// and observes its latency
+func (m *ExpvarMetrics) Cancelled(name string, latency time.Duration) {
+	metrics := m.of(name)
+	metrics.cancelled.Add(1)
+	metrics.latency.observe(latency)
+}
+
+// Panicked
+// counts a panic
+func (m *ExpvarMetrics) Panicked(name string) {
+	m.of(name).panicked.Add(1)
+}
+
+// Counts
+// returns the counts of name by metric:
+// created, fulfilled, rejected,
+// cancelled and panicked
+func (m *ExpvarMetrics) Counts(name string) map[string]int64 {
+	metrics := m.of(name)
+	return map[string]int64{
+		"created":   metrics.created.Value(),
+		"fulfilled": metrics.fulfilled.Value(),
+		"rejected":  metrics.rejected.Value(),
+		"cancelled": metrics.cancelled.Value(),
+		"panicked":  metrics.panicked.Value(),
+	}
+}
+
+// observe
+// counts latency in its bucket
+func (h *histogram) observe(latency time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	bucket := len(h.bounds)
+	for i, bound := range h.bounds {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	h.counts[bucket]++
+	h.count++
+	h.sum += latency
+}
+
+// String
+// returns the histogram as JSON with the
+// count of each bucket by its upper bound
+func (h *histogram) String() string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	buckets := make(map[string]int64, len(h.counts))
+	for i, count := range h.counts {
+		if i < len(h.bounds) {
+			buckets[h.bounds[i].String()] = count
+		} else {
+			buckets["+Inf"] = count
+		}
+	}
+	b, _ := json.Marshal(struct {
+		Buckets    map[string]int64 `json:"buckets"`
+		Count      int64            `json:"count"`
+		SumSeconds float64          `json:"sum_seconds"`
+	}{buckets, h.count, h.sum.Seconds()})
+	return string(b)
+}
@@ -0,0 +1,58 @@
+package promise
+
+// Fallback
+// calls the factories one after the other until
+// the promise one of them creates is fulfilled,
+// and returns a promise that is fulfilled like it.
+// It's rejected with an *AggregateError listing
+// the error of every promise if they're all
+// rejected. Cancelling the returned promise
+// cancels the current attempt and stops
+// the fallbacks
+func Fallback[T any](factories ...func() *Promise[T]) *Promise[T] {
+	if len(factories) == 0 {
+		panic("Promise can't fall back without factories")
+	}
+	deferred := NewDeferred[T]()
+	errs := make([]error, 0, len(factories))
+	var attempt func(i int)
+	attempt = func(i int) {
+		if deferred.Settled() {
+			return
+		}
+		current := NewDeferred[T]()
+		deferred.promise.OnCancel(func() {
+			current.promise.cancel(deferred.promise.cancelledWith())
+		})
+		tap(current.promise, func(obj T, err error) {
+			if err == nil {
+				deferred.Resolve(obj)
+				return
+			}
+			errs = append(errs, err)
+			if i+1 < len(factories) {
+				attempt(i + 1)
+				return
+			}
+			deferred.Reject(&AggregateError{Errors: errs})
+		})
+		current.follow(factories[i], func() {})
+	}
+	attempt(0)
+	return deferred.promise
+}
+
+// OrElse
+// returns a promise that is fulfilled like
+// promise, or with defaultValue if
+// promise is rejected or cancelled
+func OrElse[T any](promise *Promise[T], defaultValue T) *Promise[T] {
+	deferred := NewDeferred[T]()
+	tap(promise, func(obj T, err error) {
+		if err != nil {
+			obj = defaultValue
+		}
+		deferred.Resolve(obj)
+	})
+	return deferred.promise
+}
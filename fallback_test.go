@@ -0,0 +1,75 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallback(t *testing.T) {
+	t.Run("Falls back until a promise is fulfilled", func(t *testing.T) {
+		calls := make([]string, 0)
+		p := Fallback(
+			func() *Promise[string] {
+				calls = append(calls, "primary")
+				return rejected[string](errors.New("Famous people don't shake hands"))
+			},
+			func() *Promise[string] {
+				calls = append(calls, "cache")
+				return Promisify[string]("Someone famous")
+			},
+			func() *Promise[string] {
+				calls = append(calls, "default")
+				return Promisify[string]("Stunt Double")
+			},
+		)
+		name, err := p.Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Someone famous")
+		assert.Equal(t, calls, []string{"primary", "cache"})
+	})
+	t.Run("Rejects with every error when all promises are rejected", func(t *testing.T) {
+		errNoHands := errors.New("Famous people don't shake hands")
+		p := Fallback(
+			func() *Promise[string] {
+				return rejected[string](errNoHands)
+			},
+			func() *Promise[string] {
+				panic("Famous people don't sign autographs")
+			},
+		)
+		_, err := p.Await()
+		var aggregate *AggregateError
+		assert.ErrorAs(t, err, &aggregate)
+		assert.Len(t, aggregate.Errors, 2)
+		assert.ErrorIs(t, aggregate.Errors[0], errNoHands)
+		assert.ErrorAs(t, aggregate.Errors[1], new(*PanicError))
+	})
+	t.Run("Stops falling back when cancelled", func(t *testing.T) {
+		current := NewDeferred[string]()
+		p := Fallback(current.Promise, func() *Promise[string] {
+			assert.Fail(t, "This should never get called")
+			return nil
+		})
+		assert.True(t, p.Cancel(nil))
+		_, err := current.Promise().Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+		_, err = p.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+	})
+}
+
+func TestOrElse(t *testing.T) {
+	t.Run("Fulfills with the value of the promise", func(t *testing.T) {
+		name, err := OrElse(Promisify[string]("Someone famous"), "Stunt Double").Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Fulfills with the default value when the promise is rejected", func(t *testing.T) {
+		p := rejected[string](errors.New("Famous people don't shake hands"))
+		name, err := OrElse(p, "Stunt Double").Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Stunt Double")
+	})
+}
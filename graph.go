@@ -0,0 +1,198 @@
+package promise
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GraphNode describes a promise
+// of a Graph
+type GraphNode struct {
+	// ID of the promise
+	ID uint64
+	// ParentID is the ID of the promise it was
+	// created from, 0 for the graph's root
+	ParentID uint64
+	// Step is the kind of step
+	// that settles the promise
+	Step string
+	// Name given to the promise with Named
+	Name string
+	// State of the promise
+	State State
+	// Created is when the promise was created
+	Created time.Time
+	// Started is when the promise's step
+	// started, zero if it didn't start
+	Started time.Time
+	// Settled is when the promise was
+	// settled, zero if it's pending
+	Settled time.Time
+	// Err is the error of a rejected promise
+	Err error
+}
+
+// Graph is a snapshot of a promise and
+// the promises created from it with
+// Then and Catch
+type Graph struct {
+	// Nodes of the graph, each node
+	// comes after its parent
+	Nodes []GraphNode
+}
+
+// graphed is implemented by promises of
+// any type so a promise can walk the
+// promises derived from it
+type graphed interface {
+	walk(graph *Graph, parentID uint64)
+}
+
+// Graph
+// returns a snapshot of the promise and
+// the promises that were created from it
+// using Then and Catch, directly or not, that
+// are still reachable.
+// Ideal for visualising branching chains
+func (promise *Promise[T]) Graph() *Graph {
+	graph := &Graph{}
+	promise.walk(graph, 0)
+	return graph
+}
+
+// walk
+// adds the promise and the promises
+// derived from it to graph
+func (promise *Promise[T]) walk(graph *Graph, parentID uint64) {
+	_, err, state := promise.Peek()
+	c := promise.cancellation
+	c.mutex.Lock()
+	started := c.startedAt
+	children := c.live()
+	c.mutex.Unlock()
+	promise.resultMutex.RLock()
+	settled := promise.settledAt
+	promise.resultMutex.RUnlock()
+	graph.Nodes = append(graph.Nodes, GraphNode{
+		ID:       promise.id,
+		ParentID: parentID,
+		Step:     promise.step,
+		Name:     promise.Name(),
+		State:    state,
+		Created:  promise.createdAt,
+		Started:  started,
+		Settled:  settled,
+		Err:      err,
+	})
+	for _, child := range children {
+		if child, ok := child.(graphed); ok {
+			child.walk(graph, promise.id)
+		}
+	}
+}
+
+// Duration
+// returns how long the promise took to
+// settle since it was created, zero if
+// it's pending
+func (node GraphNode) Duration() time.Duration {
+	if node.Settled.IsZero() {
+		return 0
+	}
+	return node.Settled.Sub(node.Created)
+}
+
+// graphNodeJSON is the
+// JSON form of a GraphNode
+type graphNodeJSON struct {
+	ID       uint64     `json:"id"`
+	ParentID uint64     `json:"parent_id,omitempty"`
+	Step     string     `json:"step"`
+	Name     string     `json:"name,omitempty"`
+	State    string     `json:"state"`
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Settled  *time.Time `json:"settled,omitempty"`
+	Duration string     `json:"duration,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// MarshalJSON
+// encodes the graph as an
+// object with its nodes
+func (graph *Graph) MarshalJSON() ([]byte, error) {
+	nodes := make([]graphNodeJSON, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		encoded := graphNodeJSON{
+			ID:       node.ID,
+			ParentID: node.ParentID,
+			Step:     node.Step,
+			Name:     node.Name,
+			State:    node.State.String(),
+			Created:  node.Created,
+		}
+		if started := node.Started; !started.IsZero() {
+			encoded.Started = &started
+		}
+		if settled := node.Settled; !settled.IsZero() {
+			encoded.Settled = &settled
+			encoded.Duration = node.Duration().String()
+		}
+		if node.Err != nil {
+			encoded.Error = node.Err.Error()
+		}
+		nodes = append(nodes, encoded)
+	}
+	return json.Marshal(struct {
+		Nodes []graphNodeJSON `json:"nodes"`
+	}{nodes})
+}
+
+// JSON
+// returns the graph as indented JSON
+func (graph *Graph) JSON() (string, error) {
+	b, err := json.MarshalIndent(graph, "", "  ")
+	return string(b), err
+}
+
+// stateColors are the colors of
+// the DOT nodes by state
+var stateColors = map[State]string{
+	Pending:   "gray",
+	Fulfilled: "green",
+	Rejected:  "red",
+}
+
+// DOT
+// returns the graph in the Graphviz DOT
+// language, with a node per promise
+// colored by its state
+func (graph *Graph) DOT() string {
+	var builder strings.Builder
+	builder.WriteString("digraph promise {\n")
+	builder.WriteString("\tnode [shape=box];\n")
+	for _, node := range graph.Nodes {
+		label := fmt.Sprintf("#%d %s", node.ID, node.Step)
+		if node.Name != "" {
+			label += " " + node.Name
+		}
+		label += "\n" + node.State.String()
+		if node.State != Pending {
+			label += " in " + node.Duration().String()
+		}
+		if node.Err != nil {
+			label += "\n" + node.Err.Error()
+		}
+		fmt.Fprintf(&builder, "\t%d [label=%s, color=%s];\n", node.ID, strconv.Quote(label), stateColors[node.State])
+	}
+	for _, node := range graph.Nodes {
+		if node.ParentID != 0 {
+			fmt.Fprintf(&builder, "\t%d -> %d;\n", node.ParentID, node.ID)
+		}
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
@@ -0,0 +1,92 @@
+package promise
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph(t *testing.T) {
+	clock := useTestClock(t)
+	p := Promisify[testMessage](func() (testMessage, error) {
+		return testMessage{}, errors.New("Famous people don't shake hands")
+	}).Named("fetch-users")
+	p1 := Then(p, func(tm testMessage) (string, error) {
+		return tm.Name, nil
+	})
+	p2 := Catch(p, func(err error) (string, error) {
+		return "Stunt Double", nil
+	})
+	p3 := Then(p2, func(name string) (int, error) {
+		return len(name), nil
+	})
+	p1.Catch(func(error) {})
+	p3.Await()
+	p1.Exec()
+	clock.Advance(time.Second)
+	d := NewDeferred[string]()
+	p4 := Then(d.Promise(), func(name string) (string, error) {
+		return name, nil
+	})
+
+	t.Run("Walks the derived promises", func(t *testing.T) {
+		graph := p.Graph()
+		ids := make([]uint64, 0)
+		for _, node := range graph.Nodes {
+			ids = append(ids, node.ID)
+		}
+		assert.Equal(t, ids, []uint64{p.ID(), p1.ID(), p2.ID(), p3.ID()})
+		root := graph.Nodes[0]
+		assert.Equal(t, root.Step, stepPromisify)
+		assert.Equal(t, root.Name, "fetch-users")
+		assert.Equal(t, root.State, Rejected)
+		assert.EqualError(t, root.Err, "Famous people don't shake hands")
+		assert.Equal(t, root.Created, time.Unix(0, 0))
+		assert.Equal(t, root.Started, time.Unix(0, 0))
+		assert.Equal(t, root.Settled, time.Unix(0, 0))
+		assert.Zero(t, root.ParentID)
+		assert.Equal(t, graph.Nodes[1].ParentID, p.ID())
+		assert.Equal(t, graph.Nodes[1].State, Rejected)
+		assert.Equal(t, graph.Nodes[2].Step, stepCatch)
+		assert.Equal(t, graph.Nodes[3].ParentID, p2.ID())
+		assert.Equal(t, graph.Nodes[3].State, Fulfilled)
+		assert.Len(t, p2.Graph().Nodes, 2)
+	})
+	t.Run("Exports the graph as DOT", func(t *testing.T) {
+		dot := p.Graph().DOT()
+		assert.Contains(t, dot, "digraph promise {")
+		assert.Contains(t, dot, fmt.Sprintf(`%d [label="#%d promisify fetch-users\nrejected in 0s\nFamous people don't shake hands", color=red];`, p.ID(), p.ID()))
+		assert.Contains(t, dot, fmt.Sprintf(`%d [label="#%d then fetch-users\nfulfilled in 0s", color=green];`, p3.ID(), p3.ID()))
+		assert.Contains(t, dot, fmt.Sprintf("%d -> %d;", p.ID(), p2.ID()))
+		assert.Contains(t, dot, fmt.Sprintf("%d -> %d;", p2.ID(), p3.ID()))
+		dot = d.Promise().Graph().DOT()
+		assert.Contains(t, dot, fmt.Sprintf(`%d [label="#%d then\npending", color=gray];`, p4.ID(), p4.ID()))
+	})
+	t.Run("Exports the graph as JSON", func(t *testing.T) {
+		encoded, err := d.Promise().Graph().JSON()
+		assert.Nil(t, err)
+		var graph map[string][]map[string]any
+		assert.Nil(t, json.Unmarshal([]byte(encoded), &graph))
+		assert.Len(t, graph["nodes"], 2)
+		root := graph["nodes"][0]
+		assert.Equal(t, root["step"], stepDeferred)
+		assert.Equal(t, root["state"], "pending")
+		assert.Equal(t, root["created"], time.Unix(1, 0).Format(time.RFC3339Nano))
+		assert.Equal(t, root["started"], time.Unix(1, 0).Format(time.RFC3339Nano))
+		assert.NotContains(t, root, "settled")
+		assert.NotContains(t, root, "parent_id")
+		assert.Equal(t, graph["nodes"][1]["parent_id"], float64(d.Promise().ID()))
+		assert.NotContains(t, graph["nodes"][1], "started")
+
+		encoded, err = p.Graph().JSON()
+		assert.Nil(t, err)
+		assert.Contains(t, encoded, `"error": "Famous people don't shake hands"`)
+		assert.Contains(t, encoded, `"duration": "0s"`)
+	})
+	d.Resolve("Someone famous")
+	p4.Await()
+}
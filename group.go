@@ -0,0 +1,134 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Group deduplicates the calls made with the
+// same key while one of them is in flight, so
+// concurrent callers share one promise and see
+// the same result.
+// It's safe to use from any go routine
+type Group[K comparable, T any] struct {
+	mutex sync.Mutex
+	// calls in flight by key
+	calls map[K]*groupCall[T]
+}
+
+// groupCall is a call in flight
+// shared by its waiters
+type groupCall[T any] struct {
+	deferred *Deferred[T]
+	// waiters is the number of callers
+	// that wait for the call
+	waiters int
+}
+
+// NewGroup
+// Creates a Group without
+// calls in flight
+func NewGroup[K comparable, T any]() *Group[K, T] {
+	return &Group[K, T]{
+		calls: make(map[K]*groupCall[T]),
+	}
+}
+
+// Do
+// calls factory unless a call with key is in
+// flight and returns a promise that settles like
+// the promise of the call, see DoContext
+func (group *Group[K, T]) Do(key K, factory func() *Promise[T]) *Promise[T] {
+	return group.DoContext(context.Background(), key, factory)
+}
+
+// DoContext
+// calls factory unless a call with key is in
+// flight and returns a promise that settles like
+// the promise of the call. Every caller gets its
+// own promise: cancelling it, or ctx being done,
+// leaves the call and the call is cancelled once
+// every caller left it
+func (group *Group[K, T]) DoContext(ctx context.Context, key K, factory func() *Promise[T]) *Promise[T] {
+	call, started := group.join(key)
+	if started {
+		call.deferred.follow(factory, func() {
+			group.forget(key, call)
+		})
+	}
+	waiter := NewDeferred[T]()
+	stop := context.AfterFunc(ctx, func() {
+		waiter.promise.Cancel(context.Cause(ctx))
+	})
+	waiter.promise.OnCancel(func() {
+		stop()
+		group.leave(key, call)
+	})
+	tap(call.deferred.promise, func(obj T, err error) {
+		stop()
+		waiter.promise.settle(obj, err)
+	})
+	return waiter.promise
+}
+
+// Forget
+// makes the next call with key call its
+// factory even if a call with key is in
+// flight, its callers still share it
+func (group *Group[K, T]) Forget(key K) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+	delete(group.calls, key)
+}
+
+// InFlight
+// returns the number of
+// calls in flight
+func (group *Group[K, T]) InFlight() int {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+	return len(group.calls)
+}
+
+// join
+// returns the call in flight with key and
+// whether the caller has to start it
+func (group *Group[K, T]) join(key K) (*groupCall[T], bool) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+	call, ok := group.calls[key]
+	if !ok {
+		call = &groupCall[T]{deferred: NewDeferred[T]()}
+		group.calls[key] = call
+	}
+	call.waiters++
+	return call, !ok
+}
+
+// leave
+// removes a waiter from call and cancels
+// it once every waiter left
+func (group *Group[K, T]) leave(key K, call *groupCall[T]) {
+	group.mutex.Lock()
+	call.waiters--
+	left := call.waiters == 0
+	if left && group.calls[key] == call {
+		delete(group.calls, key)
+	}
+	group.mutex.Unlock()
+	if left {
+		call.deferred.promise.Cancel(errors.New("Every caller left the call"))
+	}
+}
+
+// forget
+// removes call from the calls in flight
+// unless it was forgotten already
+func (group *Group[K, T]) forget(key K, call *groupCall[T]) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+	if group.calls[key] == call {
+		delete(group.calls, key)
+	}
+}
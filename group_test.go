@@ -0,0 +1,80 @@
+package promise
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("Shares the call in flight with the same key", func(t *testing.T) {
+		group := NewGroup[string, string]()
+		deferred := NewDeferred[string]()
+		calls := 0
+		factory := func() *Promise[string] {
+			calls++
+			return deferred.Promise()
+		}
+		first := group.Do("famous", factory)
+		second := group.Do("famous", factory)
+		other := group.Do("stunt", func() *Promise[string] {
+			return Promisify[string]("Stunt Double")
+		})
+		assert.Equal(t, calls, 1)
+
+		deferred.Resolve("Someone famous")
+		for _, p := range []*Promise[string]{first, second} {
+			name, err := p.Await()
+			assert.Nil(t, err)
+			assert.Equal(t, name, "Someone famous")
+		}
+		name, _ := other.Await()
+		assert.Equal(t, name, "Stunt Double")
+	})
+	t.Run("Calls factory again once the call settled", func(t *testing.T) {
+		group := NewGroup[string, int]()
+		calls := 0
+		factory := func() *Promise[int] {
+			calls++
+			return Promisify[int](calls)
+		}
+		first, _ := group.Do("famous", factory).Await()
+		assert.Eventually(t, func() bool { return group.InFlight() == 0 }, time.Second, time.Millisecond)
+		second, _ := group.Do("famous", factory).Await()
+		assert.Equal(t, []int{first, second}, []int{1, 2})
+	})
+	t.Run("Forgets a call in flight", func(t *testing.T) {
+		group := NewGroup[string, string]()
+		deferred := NewDeferred[string]()
+		first := group.Do("famous", deferred.Promise)
+		group.Forget("famous")
+		second := group.Do("famous", func() *Promise[string] {
+			return Promisify[string]("Stunt Double")
+		})
+		name, _ := second.Await()
+		assert.Equal(t, name, "Stunt Double")
+		deferred.Resolve("Someone famous")
+		name, _ = first.Await()
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Cancels the call once every caller left", func(t *testing.T) {
+		group := NewGroup[string, string]()
+		deferred := NewDeferred[string]()
+		ctx, cancel := context.WithCancel(context.Background())
+		first := group.DoContext(ctx, "famous", deferred.Promise)
+		second := group.Do("famous", deferred.Promise)
+
+		cancel()
+		_, err := first.Await()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, deferred.Promise().State(), Pending)
+		assert.Equal(t, group.InFlight(), 1)
+
+		assert.True(t, second.Cancel(nil))
+		_, err = deferred.Promise().Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+		assert.Equal(t, group.InFlight(), 0)
+	})
+}
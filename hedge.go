@@ -0,0 +1,56 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// Hedge
+// calls factory and, while none of the promises
+// it created is fulfilled, calls it again every
+// time delay passes, up to maxAttempts times.
+// The returned promise is fulfilled like the first
+// attempt to be fulfilled and the other attempts
+// are cancelled, so the contexts of attempts
+// created with PromisifyContext are cancelled.
+// It's rejected with an *AggregateError if every
+// attempt is rejected. Cancelling the returned
+// promise cancels every attempt.
+// Ideal for cutting the tail latency of
+// idempotent calls
+func Hedge[T any](factory func() *Promise[T], delay time.Duration, maxAttempts int) *Promise[T] {
+	if maxAttempts <= 0 {
+		panic("Hedge maxAttempts has to be positive")
+	}
+	r := newRacer[T](true, true)
+	mutex := sync.Mutex{}
+	attempts := 0
+	stop := func() bool { return false }
+	var attempt func()
+	attempt = func() {
+		mutex.Lock()
+		if r.deferred.Settled() {
+			mutex.Unlock()
+			return
+		}
+		attempts++
+		last := attempts == maxAttempts
+		if !last {
+			stop = currentClock().AfterFunc(delay, attempt)
+		}
+		mutex.Unlock()
+		entrant := NewDeferred[T]()
+		r.enter(entrant.promise)
+		entrant.follow(factory, func() {})
+		if last {
+			r.close()
+		}
+	}
+	r.onDone(func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		stop()
+	})
+	attempt()
+	return r.deferred.promise
+}
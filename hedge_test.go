@@ -0,0 +1,88 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHedge(t *testing.T) {
+	t.Run("Fulfills without hedging when the first attempt is fast", func(t *testing.T) {
+		clock := useTestClock(t)
+		calls := 0
+		p := Hedge(func() *Promise[string] {
+			calls++
+			return Promisify[string]("Someone famous")
+		}, time.Second, 3)
+		name, err := p.Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Someone famous")
+		clock.Advance(time.Second)
+		assert.Equal(t, calls, 1)
+	})
+	t.Run("Starts another attempt after the delay and cancels the loser", func(t *testing.T) {
+		clock := useTestClock(t)
+		deferreds := []*Deferred[string]{NewDeferred[string](), NewDeferred[string](), NewDeferred[string]()}
+		calls := 0
+		p := Hedge(func() *Promise[string] {
+			calls++
+			return deferreds[calls-1].Promise()
+		}, time.Second, 3)
+		assert.Equal(t, calls, 1)
+		clock.Advance(time.Second)
+		assert.Equal(t, calls, 2)
+
+		deferreds[1].Resolve("Stunt Double")
+		name, err := p.Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Stunt Double")
+		_, err = deferreds[0].Promise().Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+		clock.Advance(time.Second)
+		assert.Equal(t, calls, 2)
+	})
+	t.Run("Rejects with every error when all attempts fail", func(t *testing.T) {
+		clock := useTestClock(t)
+		calls := 0
+		p := Hedge(func() *Promise[string] {
+			calls++
+			return rejected[string](errors.New("Famous people don't shake hands"))
+		}, time.Second, 2)
+		clock.Advance(time.Second)
+		_, err := p.Await()
+		var aggregate *AggregateError
+		assert.ErrorAs(t, err, &aggregate)
+		assert.Len(t, aggregate.Errors, 2)
+		assert.Equal(t, calls, 2)
+	})
+	t.Run("Rejects with the panic of the factory", func(t *testing.T) {
+		useTestClock(t)
+		p := Hedge(func() *Promise[string] {
+			panic("Famous people don't shake hands")
+		}, time.Second, 1)
+		_, err := p.Await()
+		var panicked *PanicError
+		assert.ErrorAs(t, err, &panicked)
+	})
+	t.Run("Cancels the contexts of every attempt when cancelled", func(t *testing.T) {
+		clock := useTestClock(t)
+		contexts := make(chan context.Context, 2)
+		p := Hedge(func() *Promise[string] {
+			return PromisifyContext[string](context.Background(), func(ctx context.Context) (string, error) {
+				contexts <- ctx
+				<-ctx.Done()
+				return "", ctx.Err()
+			})
+		}, time.Second, 2)
+		clock.Advance(time.Second)
+		first, second := <-contexts, <-contexts
+		assert.True(t, p.Cancel(nil))
+		<-first.Done()
+		<-second.Done()
+		_, err := p.Await()
+		assert.ErrorAs(t, err, new(*CancelledError))
+	})
+}
@@ -0,0 +1,54 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// lazy holds the execution of a lazy
+// promise until it's started
+type lazy struct {
+	once sync.Once
+	run  func()
+}
+
+// Lazy
+// Creates a promise from a function and its
+// arguments like Promisify, but only runs the
+// function once the promise is started with
+// Start or subscribed to with Then, Catch,
+// Finally, Exec or Await.
+// Ideal for building promise pipelines up
+// front and running them on demand
+func Lazy[T any](fn any, args ...any) *Promise[T] {
+	if fn == nil || !isFunction(fn) {
+		panic(fmt.Sprintf("Promise can't lazily run %T as it isn't a function", fn))
+	}
+	function := reflect.ValueOf(fn)
+	values := planOf(function.Type()).values(args)
+	promise := newPromise[T](context.Background(), stepLazy)
+	// the step is counted right away so a
+	// lazy promise cancelled before it's
+	// started settles like any other
+	promise.wg.Add(1)
+	promise.lazy = &lazy{
+		run: func() {
+			promise.queue.enqueue(promise.instrument(promise.step, func() {
+				execute(promise, funcRunner[T], function, values...)
+			}))
+		},
+	}
+	return promise
+}
+
+// Start
+// starts executing a lazy promise.
+// It does nothing if the promise isn't
+// lazy or already started
+func (promise *Promise[T]) Start() {
+	if promise.lazy != nil {
+		promise.lazy.once.Do(promise.lazy.run)
+	}
+}
@@ -0,0 +1,60 @@
+package promise
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazy(t *testing.T) {
+	t.Run("Doesn't run until it's awaited", func(t *testing.T) {
+		calls := atomic.Int32{}
+		p := Lazy[testMessage](func(name string, subject string) (testMessage, error) {
+			calls.Add(1)
+			return testMessage{
+				Name:    name,
+				Subject: subject,
+			}, nil
+		}, "Someone famous", "Hi famous person")
+		assert.Equal(t, calls.Load(), int32(0))
+		msg, err := p.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, msg, testMessage{
+			Name:    "Someone famous",
+			Subject: "Hi famous person",
+		})
+		assert.Equal(t, calls.Load(), int32(1))
+	})
+	t.Run("Runs when it's subscribed to", func(t *testing.T) {
+		calls := atomic.Int32{}
+		p := Lazy[string](func() (string, error) {
+			calls.Add(1)
+			return "Someone famous", nil
+		})
+		p1 := Then(p, func(name string) (int, error) {
+			return len(name), nil
+		})
+		length, err := p1.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, length, len("Someone famous"))
+		assert.Equal(t, calls.Load(), int32(1))
+	})
+	t.Run("Only starts once", func(t *testing.T) {
+		calls := atomic.Int32{}
+		p := Lazy[int](func() (int, error) {
+			return int(calls.Add(1)), nil
+		})
+		p.Start()
+		p.Start()
+		count, err := p.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, count, 1)
+		assert.Equal(t, calls.Load(), int32(1))
+	})
+	t.Run("Panics if it isn't given a function", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Lazy[string]("Someone famous")
+		})
+	})
+}
@@ -0,0 +1,138 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LeakedPromise describes a promise
+// that is still pending
+type LeakedPromise struct {
+	// ID identifies the promise
+	ID uint64
+	// Type is the type of the promise's value
+	Type string
+	// Created is when the promise was created
+	Created time.Time
+	// Stack is the call stack that
+	// created the promise
+	Stack string
+}
+
+var (
+	// trackLeaks is true while pending
+	// promises are tracked
+	trackLeaks atomic.Bool
+	leaksMutex sync.Mutex
+	// pendingPromises are the tracked promises
+	// that aren't settled yet
+	pendingPromises = map[uint64]*LeakedPromise{}
+	// packagePath is the import path of the package
+	packagePath = reflect.TypeOf(queue{}).PkgPath()
+)
+
+// SetLeakTracking
+// starts or stops tracking the promises that
+// are created from now on until they settle,
+// and returns whether they were tracked before.
+// Tracking captures the call stack of every
+// promise so it's meant for tests and debugging
+func SetLeakTracking(enabled bool) bool {
+	return trackLeaks.Swap(enabled)
+}
+
+// LeakedPromises
+// returns the tracked promises that are
+// still pending, oldest first
+func LeakedPromises() []LeakedPromise {
+	leaksMutex.Lock()
+	defer leaksMutex.Unlock()
+	leaks := make([]LeakedPromise, 0, len(pendingPromises))
+	for _, leak := range pendingPromises {
+		leaks = append(leaks, *leak)
+	}
+	sort.Slice(leaks, func(i, j int) bool {
+		return leaks[i].ID < leaks[j].ID
+	})
+	return leaks
+}
+
+// String
+// describes the leaked promise
+func (leak LeakedPromise) String() string {
+	return fmt.Sprintf("Promise #%d of %s created at %v is still pending:\n%s",
+		leak.ID, leak.Type, leak.Created.Format(time.RFC3339Nano), leak.Stack)
+}
+
+// track
+// starts tracking the promise if leak
+// tracking is enabled
+func (promise *Promise[T]) track() {
+	if !trackLeaks.Load() {
+		return
+	}
+	promise.tracked = true
+	leak := &LeakedPromise{
+		ID:      promise.id,
+		Type:    reflect.TypeOf((*T)(nil)).Elem().String(),
+		Created: time.Now(),
+		Stack:   callerStack(),
+	}
+	leaksMutex.Lock()
+	defer leaksMutex.Unlock()
+	pendingPromises[promise.id] = leak
+}
+
+// untrack
+// stops tracking the promise once it settled
+func (promise *Promise[T]) untrack() {
+	if !promise.tracked {
+		return
+	}
+	leaksMutex.Lock()
+	defer leaksMutex.Unlock()
+	delete(pendingPromises, promise.id)
+}
+
+// callerStack
+// returns the call stack of the caller
+// of the package
+func callerStack() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	stack := strings.Builder{}
+	inPackage := true
+	for {
+		frame, more := frames.Next()
+		// skip the frames of the package
+		// until it's called by a user
+		if inPackage && isPackageFrame(frame) {
+			if !more {
+				break
+			}
+			continue
+		}
+		inPackage = false
+		fmt.Fprintf(&stack, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return stack.String()
+}
+
+// isPackageFrame
+// returns true if the frame is in the
+// package's own code
+func isPackageFrame(frame runtime.Frame) bool {
+	function := strings.TrimPrefix(frame.Function, packagePath)
+	return function != frame.Function && strings.HasPrefix(function, ".") &&
+		!strings.HasSuffix(frame.File, "_test.go")
+}
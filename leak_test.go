@@ -0,0 +1,50 @@
+package promise
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func useLeakTracking(t *testing.T) {
+	previous := SetLeakTracking(true)
+	t.Cleanup(func() {
+		SetLeakTracking(previous)
+	})
+}
+
+func leakedIDs() []uint64 {
+	ids := make([]uint64, 0)
+	for _, leak := range LeakedPromises() {
+		ids = append(ids, leak.ID)
+	}
+	return ids
+}
+
+func TestLeakedPromises(t *testing.T) {
+	t.Run("Reports pending promises with their creation stack", func(t *testing.T) {
+		useLeakTracking(t)
+		d := NewDeferred[testMessage]()
+		p := Then(d.Promise(), func(tm testMessage) (string, error) {
+			return tm.Name, nil
+		})
+		assert.Subset(t, leakedIDs(), []uint64{d.Promise().id, p.id})
+		for _, leak := range LeakedPromises() {
+			if leak.ID == d.Promise().id {
+				assert.Equal(t, leak.Type, "promise.testMessage")
+				assert.Contains(t, leak.Stack, "TestLeakedPromises")
+				assert.NotContains(t, leak.Stack, "NewDeferred")
+				assert.Contains(t, leak.String(), "is still pending")
+			}
+		}
+		d.Resolve(testMessage{Name: "Someone famous"})
+		p.Await()
+		assert.NotContains(t, leakedIDs(), d.Promise().id)
+		assert.NotContains(t, leakedIDs(), p.id)
+	})
+	t.Run("Doesn't track promises when it's disabled", func(t *testing.T) {
+		SetLeakTracking(false)
+		d := NewDeferred[testMessage]()
+		assert.NotContains(t, leakedIDs(), d.Promise().id)
+	})
+}
@@ -0,0 +1,178 @@
+package promise
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotLoaded is the error the promise of a
+// key is rejected with when the batch function
+// of its Loader doesn't return it
+var ErrKeyNotLoaded = errors.New("Loader batch didn't return the key")
+
+// LoaderOptions configures
+// a Loader
+type LoaderOptions struct {
+	// Wait is how long the loader collects keys
+	// before it loads them, 1ms if zero
+	Wait time.Duration
+	// MaxBatch is the number of keys that loads
+	// the batch right away, unlimited if zero
+	MaxBatch int
+	// NoCache makes every Load load its key
+	// even if it was loaded before
+	NoCache bool
+}
+
+// Loader batches the keys loaded within a small
+// window into one call of its batch function and
+// caches the promise of every key, so one Loader
+// per request loads every key once.
+// It's safe to use from any go routine
+type Loader[K comparable, V any] struct {
+	batch   func(keys []K) (map[K]V, error)
+	options LoaderOptions
+	mutex   sync.Mutex
+	// cache of the results by key
+	cache map[K]*waitlist[V]
+	// pending is the batch that
+	// collects the keys
+	pending *loaderBatch[K, V]
+}
+
+// loaderBatch is the keys that are
+// loaded by one call of the batch
+type loaderBatch[K comparable, V any] struct {
+	keys      []K
+	waitlists map[K]*waitlist[V]
+	// stop stops the timer
+	// that loads the batch
+	stop       func() bool
+	dispatched bool
+}
+
+// NewLoader
+// Creates a Loader that loads the
+// keys with batch
+func NewLoader[K comparable, V any](batch func(keys []K) (map[K]V, error), options LoaderOptions) *Loader[K, V] {
+	if options.Wait <= 0 {
+		options.Wait = time.Millisecond
+	}
+	return &Loader[K, V]{
+		batch:   batch,
+		options: options,
+		cache:   make(map[K]*waitlist[V]),
+	}
+}
+
+// Load
+// returns a promise that is fulfilled with
+// the value batch returns for key. It's rejected
+// with the error of batch, or ErrKeyNotLoaded if
+// batch didn't return the key. The rejected
+// keys aren't cached
+func (loader *Loader[K, V]) Load(key K) *Promise[V] {
+	loader.mutex.Lock()
+	list, ok := loader.cache[key]
+	var full *loaderBatch[K, V]
+	if !ok {
+		list, full = loader.enqueue(key)
+	}
+	loader.mutex.Unlock()
+	if full != nil {
+		loader.dispatch(full)
+	}
+	return list.wait()
+}
+
+// Clear
+// removes key from the cache,
+// the next Load loads it again
+func (loader *Loader[K, V]) Clear(key K) {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+	delete(loader.cache, key)
+}
+
+// ClearAll
+// empties the cache
+func (loader *Loader[K, V]) ClearAll() {
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+	loader.cache = make(map[K]*waitlist[V])
+}
+
+// enqueue
+// adds key to the pending batch and returns
+// its waitlist and the batch if it's full,
+// the caller holds the mutex
+func (loader *Loader[K, V]) enqueue(key K) (*waitlist[V], *loaderBatch[K, V]) {
+	batch := loader.pending
+	if batch == nil {
+		batch = &loaderBatch[K, V]{waitlists: make(map[K]*waitlist[V])}
+		batch.stop = currentClock().AfterFunc(loader.options.Wait, func() {
+			loader.dispatch(batch)
+		})
+		loader.pending = batch
+	}
+	list, ok := batch.waitlists[key]
+	if !ok {
+		list = &waitlist[V]{}
+		batch.waitlists[key] = list
+		batch.keys = append(batch.keys, key)
+	}
+	if !loader.options.NoCache {
+		loader.cache[key] = list
+	}
+	if loader.options.MaxBatch > 0 && len(batch.keys) >= loader.options.MaxBatch {
+		loader.pending = nil
+		return list, batch
+	}
+	return list, nil
+}
+
+// dispatch
+// calls batch with the keys of a batch
+// and settles the waitlist of every key
+func (loader *Loader[K, V]) dispatch(batch *loaderBatch[K, V]) {
+	loader.mutex.Lock()
+	if batch.dispatched {
+		loader.mutex.Unlock()
+		return
+	}
+	batch.dispatched = true
+	if loader.pending == batch {
+		loader.pending = nil
+	}
+	loader.mutex.Unlock()
+	batch.stop()
+	tap(Promisify[map[K]V](loader.batch, batch.keys), func(values map[K]V, err error) {
+		for _, key := range batch.keys {
+			list := batch.waitlists[key]
+			value, ok := values[key]
+			switch {
+			case err != nil:
+				loader.reject(key, list, err)
+			case !ok:
+				loader.reject(key, list, fmt.Errorf("%w: %v", ErrKeyNotLoaded, key))
+			default:
+				list.settle(value, nil)
+			}
+		}
+	})
+}
+
+// reject
+// rejects the waitlist of key
+// and removes it from the cache
+func (loader *Loader[K, V]) reject(key K, list *waitlist[V], err error) {
+	loader.mutex.Lock()
+	if loader.cache[key] == list {
+		delete(loader.cache, key)
+	}
+	loader.mutex.Unlock()
+	var zero V
+	list.settle(zero, err)
+}
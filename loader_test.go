@@ -0,0 +1,122 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordedBatches records the keys of
+// every call of a batch function
+type recordedBatches struct {
+	mutex   sync.Mutex
+	batches [][]string
+}
+
+func (r *recordedBatches) batch(keys []string) (map[string]int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.batches = append(r.batches, keys)
+	values := make(map[string]int)
+	for _, key := range keys {
+		if key != "missing" {
+			values[key] = len(key)
+		}
+	}
+	return values, nil
+}
+
+func (r *recordedBatches) calls() [][]string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.batches
+}
+
+func TestLoader(t *testing.T) {
+	t.Run("Loads the keys of the window in one batch", func(t *testing.T) {
+		clock := useTestClock(t)
+		recorded := &recordedBatches{}
+		loader := NewLoader(recorded.batch, LoaderOptions{Wait: time.Millisecond})
+		first := loader.Load("John")
+		second := loader.Load("Johnathon")
+		again := loader.Load("John")
+		assert.Len(t, recorded.calls(), 0)
+		clock.Advance(time.Millisecond)
+		for p, expected := range map[*Promise[int]]int{first: 4, second: 9, again: 4} {
+			n, err := p.Await()
+			assert.Nil(t, err)
+			assert.Equal(t, n, expected)
+		}
+		assert.Equal(t, recorded.calls(), [][]string{{"John", "Johnathon"}})
+	})
+	t.Run("Loads a full batch right away", func(t *testing.T) {
+		useTestClock(t)
+		recorded := &recordedBatches{}
+		loader := NewLoader(recorded.batch, LoaderOptions{MaxBatch: 2})
+		first := loader.Load("John")
+		second := loader.Load("Jane")
+		third := loader.Load("Johnathon")
+		first.Await()
+		second.Await()
+		assert.Equal(t, recorded.calls(), [][]string{{"John", "Jane"}})
+		assert.Equal(t, third.State(), Pending)
+	})
+	t.Run("Caches the loaded keys", func(t *testing.T) {
+		clock := useTestClock(t)
+		recorded := &recordedBatches{}
+		loader := NewLoader(recorded.batch, LoaderOptions{})
+		p := loader.Load("John")
+		clock.Advance(time.Millisecond)
+		p.Await()
+		n, _ := loader.Load("John").Await()
+		assert.Equal(t, n, 4)
+		assert.Len(t, recorded.calls(), 1)
+
+		loader.Clear("John")
+		p = loader.Load("John")
+		clock.Advance(time.Millisecond)
+		p.Await()
+		assert.Len(t, recorded.calls(), 2)
+	})
+	t.Run("Doesn't keep a promise per Load of a cached key", func(t *testing.T) {
+		clock := useTestClock(t)
+		recorded := &recordedBatches{}
+		loader := NewLoader(recorded.batch, LoaderOptions{})
+		p := loader.Load("John")
+		clock.Advance(time.Millisecond)
+		p.Await()
+		for i := 0; i < 100; i++ {
+			loader.Load("John").Await()
+		}
+		list := loader.cache["John"]
+		list.mutex.Lock()
+		defer list.mutex.Unlock()
+		assert.Empty(t, list.waiting)
+	})
+	t.Run("Rejects the keys the batch didn't return", func(t *testing.T) {
+		clock := useTestClock(t)
+		recorded := &recordedBatches{}
+		loader := NewLoader(recorded.batch, LoaderOptions{})
+		p := loader.Load("missing")
+		clock.Advance(time.Millisecond)
+		_, err := p.Await()
+		assert.ErrorIs(t, err, ErrKeyNotLoaded)
+		assert.EqualError(t, err, "Loader batch didn't return the key: missing")
+	})
+	t.Run("Rejects every key with the error of the batch", func(t *testing.T) {
+		clock := useTestClock(t)
+		loader := NewLoader(func(keys []string) (map[string]int, error) {
+			return nil, errors.New("Famous people don't shake hands")
+		}, LoaderOptions{})
+		first := loader.Load("John")
+		second := loader.Load("Jane")
+		clock.Advance(time.Millisecond)
+		for _, p := range []*Promise[int]{first, second} {
+			_, err := p.Await()
+			assert.EqualError(t, err, "Famous people don't shake hands")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package promise
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	loggerMutex sync.RWMutex
+	// logger logs the failures of
+	// the promises created from now on
+	logger *slog.Logger
+	// logSettlements is true while the
+	// settlements are logged too
+	logSettlements atomic.Bool
+)
+
+// SetLogger
+// sets the *slog.Logger that logs the unhandled
+// rejections and the panics of the promises
+// created from now on and returns the previous
+// one. While a logger is set unhandled
+// rejections are logged instead of panicking,
+// nil restores panicking.
+// Promises created from a promise use the
+// logger of the promise they're created from
+func SetLogger(l *slog.Logger) *slog.Logger {
+	loggerMutex.Lock()
+	defer loggerMutex.Unlock()
+	previous := logger
+	logger = l
+	return previous
+}
+
+// SetLogSettlements
+// makes the loggers log every settlement at
+// the debug level and returns whether they
+// did before
+func SetLogSettlements(enabled bool) bool {
+	return logSettlements.Swap(enabled)
+}
+
+// currentLogger
+// returns the logger of the package
+func currentLogger() *slog.Logger {
+	loggerMutex.RLock()
+	defer loggerMutex.RUnlock()
+	return logger
+}
+
+// log
+// logs msg with the promise's attributes
+func (promise *Promise[T]) log(level slog.Level, msg string, err error, attrs ...slog.Attr) {
+	attrs = append([]slog.Attr{
+		slog.Uint64("promise_id", promise.id),
+		slog.String("name", promise.Name()),
+		slog.String("step", promise.step),
+		slog.Duration("duration", currentClock().Now().Sub(promise.createdAt)),
+	}, attrs...)
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	if promise.origin != nil {
+		attrs = append(attrs, slog.String("origin", promise.origin.String()))
+	}
+	promise.logger.LogAttrs(promise.ctx, level, msg, attrs...)
+}
+
+// logUnhandled
+// logs the promise's unhandled rejection,
+// returns false if there's no logger
+func (promise *Promise[T]) logUnhandled(err error) bool {
+	if promise.logger == nil {
+		return false
+	}
+	promise.log(slog.LevelError, "Promise rejection was not handled", err)
+	return true
+}
+
+// logPanic
+// logs a panic of the step or
+// the subscriber of the promise
+func (promise *Promise[T]) logPanic(r any, err error, subscriber string) {
+	if promise.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{slog.Any("panic", r)}
+	if subscriber != "" {
+		attrs = append(attrs, slog.String("subscriber", subscriber))
+	}
+	promise.log(slog.LevelError, "Promise recovered from a panic", err, attrs...)
+}
+
+// logSettle
+// logs the promise's settlement
+// if settlements are logged
+func (promise *Promise[T]) logSettle(err error) {
+	if promise.logger == nil || !logSettlements.Load() {
+		return
+	}
+	state := Fulfilled
+	if err != nil {
+		state = Rejected
+	}
+	promise.log(slog.LevelDebug, "Promise was settled", err, slog.String("state", state.String()))
+}
@@ -0,0 +1,117 @@
+package promise
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// logBuffer collects the
+// records of a JSON logger
+type logBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *logBuffer) records() []map[string]any {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	records := make([]map[string]any, 0)
+	for _, line := range strings.Split(strings.TrimSpace(b.buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		json.Unmarshal([]byte(line), &record)
+		records = append(records, record)
+	}
+	return records
+}
+
+func useLogger(t *testing.T) *logBuffer {
+	buffer := &logBuffer{}
+	previous := SetLogger(slog.New(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() {
+		SetLogger(previous)
+	})
+	return buffer
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("Logs unhandled rejections instead of panicking", func(t *testing.T) {
+		buffer := useLogger(t)
+		p := Promisify[string](func() (string, error) {
+			return "", errors.New("Famous people don't shake hands")
+		}).Named("fetch-users")
+		assert.NotPanics(t, p.Exec)
+
+		records := buffer.records()
+		assert.Len(t, records, 1)
+		assert.Equal(t, records[0]["level"], "ERROR")
+		assert.Equal(t, records[0]["msg"], "Promise rejection was not handled")
+		assert.Equal(t, records[0]["promise_id"], float64(p.ID()))
+		assert.Equal(t, records[0]["name"], "fetch-users")
+		assert.Equal(t, records[0]["step"], stepPromisify)
+		assert.Equal(t, records[0]["error"], "Famous people don't shake hands")
+		assert.Contains(t, records[0], "duration")
+	})
+	t.Run("Logs panics", func(t *testing.T) {
+		buffer := useLogger(t)
+		p := Promisify[string](func() (string, error) {
+			panic("Famous people don't shake hands")
+		})
+		p1 := Catch(p, func(err error) (string, error) {
+			return "Stunt Double", nil
+		})
+		p1.Then(func(string) {
+			panic("Stunt Double")
+		})
+		p1.Catch(func(error) {})
+		p1.Exec()
+
+		records := buffer.records()
+		assert.Len(t, records, 2)
+		assert.Equal(t, records[0]["msg"], "Promise recovered from a panic")
+		assert.Equal(t, records[0]["promise_id"], float64(p.ID()))
+		assert.Equal(t, records[0]["panic"], "Famous people don't shake hands")
+		assert.NotContains(t, records[0], "subscriber")
+		assert.Equal(t, records[1]["promise_id"], float64(p1.ID()))
+		assert.Equal(t, records[1]["panic"], "Stunt Double")
+		assert.Equal(t, records[1]["subscriber"], stepThen)
+		assert.Contains(t, records[1]["error"], "Stunt Double")
+	})
+	t.Run("Logs settlements when enabled", func(t *testing.T) {
+		buffer := useLogger(t)
+		previous := SetLogSettlements(true)
+		t.Cleanup(func() {
+			SetLogSettlements(previous)
+		})
+		p := Promisify[string]("Someone famous")
+		p1 := Then(p, func(name string) (string, error) {
+			return "", errors.New("Famous people don't shake hands")
+		})
+		p1.Catch(func(error) {})
+		p1.Exec()
+
+		records := buffer.records()
+		assert.Len(t, records, 2)
+		assert.Equal(t, records[0]["level"], "DEBUG")
+		assert.Equal(t, records[0]["msg"], "Promise was settled")
+		assert.Equal(t, records[0]["state"], "fulfilled")
+		assert.Equal(t, records[1]["step"], stepThen)
+		assert.Equal(t, records[1]["state"], "rejected")
+		assert.Equal(t, records[1]["error"], "Famous people don't shake hands")
+	})
+}
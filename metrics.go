@@ -0,0 +1,150 @@
+package promise
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Metrics counts the promises created while
+// it's set with SetMetrics, by their name.
+// Its methods are called from the promises'
+// go routines so they must be safe for
+// concurrent use and shouldn't block
+type Metrics interface {
+	// Created is called when a promise is created
+	Created(name string)
+	// Fulfilled is called when a promise is fulfilled
+	// with the time it took since it was created
+	Fulfilled(name string, latency time.Duration)
+	// Rejected is called when a promise is rejected
+	// with the time it took since it was created
+	Rejected(name string, latency time.Duration)
+	// Cancelled is called instead of Rejected
+	// when a promise is rejected because it
+	// was cancelled
+	Cancelled(name string, latency time.Duration)
+	// Panicked is called when the step or a
+	// Then or Catch subscriber of a promise
+	// panics, a panicking step also rejects
+	// the promise
+	Panicked(name string)
+}
+
+// RenamingMetrics is a Metrics that moves the
+// count of a created promise to its new name
+// when it's named after it was created
+type RenamingMetrics interface {
+	Metrics
+	// Renamed is called when a pending promise
+	// counted as created under from is named to
+	Renamed(from, to string)
+}
+
+// naming holds the name
+// of a promise
+type naming struct {
+	mutex sync.Mutex
+	name  string
+	// settled is true once the promise's
+	// outcome was counted under its name
+	settled bool
+}
+
+var (
+	metricsMutex sync.RWMutex
+	// metrics counts the promises
+	// created from now on
+	metrics Metrics
+)
+
+// SetMetrics
+// sets the Metrics that counts the promises
+// created from now on and returns the previous
+// one, nil stops counting.
+// Promises created from a promise use the
+// Metrics of the promise they're created from
+func SetMetrics(m Metrics) Metrics {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	previous := metrics
+	metrics = m
+	return previous
+}
+
+// currentMetrics
+// returns the Metrics of the package
+func currentMetrics() Metrics {
+	metricsMutex.RLock()
+	defer metricsMutex.RUnlock()
+	return metrics
+}
+
+// Named
+// names the promise so its metrics are
+// reported under name, the promises created
+// from it afterwards inherit the name.
+// Its creation was counted under its previous
+// name, a RenamingMetrics moves it to name
+// if the promise is still pending
+func (promise *Promise[T]) Named(name string) *Promise[T] {
+	promise.naming.mutex.Lock()
+	from := promise.naming.name
+	promise.naming.name = name
+	renamed := !promise.naming.settled && from != name
+	promise.naming.mutex.Unlock()
+	if m, ok := promise.metrics.(RenamingMetrics); ok && renamed {
+		m.Renamed(from, name)
+	}
+	return promise
+}
+
+// Name
+// returns the name of the promise
+func (promise *Promise[T]) Name() string {
+	promise.naming.mutex.Lock()
+	defer promise.naming.mutex.Unlock()
+	return promise.naming.name
+}
+
+// reportCreated
+// reports the promise's creation
+// to its Metrics
+func (promise *Promise[T]) reportCreated() {
+	if promise.metrics != nil {
+		promise.metrics.Created(promise.Name())
+	}
+}
+
+// measureSettle
+// reports the promise's outcome and
+// latency to its Metrics
+func (promise *Promise[T]) measureSettle(err error, latency time.Duration) {
+	if promise.metrics == nil {
+		return
+	}
+	promise.naming.mutex.Lock()
+	promise.naming.settled = true
+	name := promise.naming.name
+	promise.naming.mutex.Unlock()
+	var cancelled *CancelledError
+	switch {
+	case err == nil:
+		promise.metrics.Fulfilled(name, latency)
+	case errors.As(err, &cancelled):
+		promise.metrics.Cancelled(name, latency)
+	default:
+		promise.metrics.Rejected(name, latency)
+	}
+}
+
+// panicked
+// reports that the step or the
+// subscriber of the promise panicked
+func (promise *Promise[T]) panicked(r any, err error, subscriber string) {
+	promise.tracePanic(r, err, subscriber)
+	promise.logPanic(r, err, subscriber)
+	if promise.metrics != nil {
+		promise.metrics.Panicked(promise.Name())
+	}
+}
@@ -0,0 +1,109 @@
+package promise
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// metricsVars makes the expvar names of
+// the tests unique when they're repeated
+var metricsVars atomic.Int64
+
+func useMetrics(t *testing.T) *ExpvarMetrics {
+	metrics := NewExpvarMetrics(fmt.Sprintf("%s-%d", t.Name(), metricsVars.Add(1)))
+	previous := SetMetrics(metrics)
+	t.Cleanup(func() {
+		SetMetrics(previous)
+	})
+	return metrics
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("Counts the promises by name", func(t *testing.T) {
+		metrics := useMetrics(t)
+		p := Promisify[testMessage](func() (testMessage, error) {
+			return testMessage{Name: "Someone famous"}, nil
+		}).Named("fetch-users")
+		p1 := Then(p, func(tm testMessage) (string, error) {
+			return "", errors.New("Famous people don't shake hands")
+		})
+		p2 := Catch(p1, func(err error) (string, error) {
+			panic("Stunt Double")
+		})
+		p2.Catch(func(error) {})
+		p2.Exec()
+		d := NewDeferred[string]()
+		d.Promise().Named("fetch-users").Cancel(nil)
+
+		assert.Equal(t, metrics.Counts("fetch-users"), map[string]int64{
+			"created":   4,
+			"fulfilled": 1,
+			"rejected":  2,
+			"cancelled": 1,
+			"panicked":  1,
+		})
+		assert.Equal(t, p1.Name(), "fetch-users")
+	})
+	t.Run("Counts a renamed derived promise under its own name", func(t *testing.T) {
+		metrics := useMetrics(t)
+		p := Promisify[string]("Someone famous").Named("fetch")
+		Then(p, func(name string) (int, error) {
+			return len(name), nil
+		}).Named("transform").Await()
+		assert.Equal(t, metrics.Counts("fetch")["created"], int64(1))
+		assert.Equal(t, metrics.Counts("fetch")["fulfilled"], int64(1))
+		assert.Equal(t, metrics.Counts("transform")["created"], int64(1))
+		assert.Equal(t, metrics.Counts("transform")["fulfilled"], int64(1))
+	})
+	t.Run("Counts a pending promise as created", func(t *testing.T) {
+		metrics := useMetrics(t)
+		d := NewDeferred[string]()
+		assert.Equal(t, metrics.Counts("")["created"], int64(1))
+		d.Promise().Named("stuck")
+		assert.Equal(t, metrics.Counts("")["created"], int64(0))
+		assert.Equal(t, metrics.Counts("stuck")["created"], int64(1))
+		d.Resolve("Someone famous")
+		d.Promise().Named("late")
+		assert.Equal(t, metrics.Counts("stuck")["created"], int64(1))
+		assert.Equal(t, metrics.Counts("stuck")["fulfilled"], int64(1))
+		assert.Zero(t, metrics.Counts("late")["created"])
+	})
+	t.Run("Counts unnamed promises under the empty name", func(t *testing.T) {
+		metrics := useMetrics(t)
+		Promisify[string]("Someone famous").Await()
+		assert.Equal(t, metrics.Counts("")["created"], int64(1))
+		assert.Equal(t, metrics.Counts("")["fulfilled"], int64(1))
+	})
+	t.Run("Observes the settle latency", func(t *testing.T) {
+		clock := useTestClock(t)
+		metrics := useMetrics(t)
+		p := Delay(2*time.Second, "Someone famous").Named("delay")
+		clock.Advance(2 * time.Second)
+		p.Await()
+
+		var latency struct {
+			Buckets    map[string]int64 `json:"buckets"`
+			Count      int64            `json:"count"`
+			SumSeconds float64          `json:"sum_seconds"`
+		}
+		vars := metrics.vars.Get("delay").(*expvar.Map)
+		assert.Nil(t, json.Unmarshal([]byte(vars.Get("latency").String()), &latency))
+		assert.Equal(t, latency.Count, int64(1))
+		assert.Equal(t, latency.SumSeconds, 2.0)
+		assert.Equal(t, latency.Buckets["5s"], int64(1))
+		assert.Equal(t, latency.Buckets["1s"], int64(0))
+	})
+	t.Run("Stops counting when the metrics are unset", func(t *testing.T) {
+		metrics := useMetrics(t)
+		SetMetrics(nil)
+		Promisify[string]("Someone famous").Named("unset").Await()
+		assert.Zero(t, metrics.Counts("unset")["created"])
+	})
+}
@@ -0,0 +1,145 @@
+// Package otelpromise
+// traces promises with OpenTelemetry.
+// Each promise gets a span that is a child of
+// the span of the promise it was created from
+// with Then or Catch, or of the span in the
+// context given to PromisifyContext
+package otelpromise
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	promise "github.com/Shehats/go-promisify"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of
+// the tracer of the package
+const instrumentationName = "github.com/Shehats/go-promisify/otelpromise"
+
+// Tracer is a promise.ContextTracer
+// that creates a span per promise
+type Tracer struct {
+	tracer trace.Tracer
+	mutex  sync.Mutex
+	// spans of the promises that
+	// aren't settled yet
+	spans map[uint64]trace.Span
+}
+
+var _ promise.ContextTracer = (*Tracer)(nil)
+
+// NewTracer
+// Creates a Tracer that creates spans with a
+// tracer of provider, set it with promise.SetTracer
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer: provider.Tracer(instrumentationName),
+		spans:  make(map[uint64]trace.Span),
+	}
+}
+
+// attributes
+// returns the attributes of a promise's span
+func attributes(event promise.TraceEvent) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Int64("promise.id", int64(event.ID)),
+		attribute.String("promise.step", event.Step),
+	}
+	if event.ParentID != 0 {
+		attrs = append(attrs, attribute.Int64("promise.parent_id", int64(event.ParentID)))
+	}
+	return attrs
+}
+
+// span
+// returns the span of a pending promise
+func (tracer *Tracer) span(id uint64) (trace.Span, bool) {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	span, ok := tracer.spans[id]
+	return span, ok
+}
+
+// TraceContext
+// starts the span of the promise as a child
+// of the span in the context it inherits
+func (tracer *Tracer) TraceContext(event promise.TraceEvent) context.Context {
+	ctx, span := tracer.tracer.Start(event.Context, "promise."+event.Step,
+		trace.WithTimestamp(event.Time),
+		trace.WithAttributes(attributes(event)...),
+	)
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	tracer.spans[event.ID] = span
+	return ctx
+}
+
+// OnCreate
+// does nothing as the span is
+// started by TraceContext
+func (tracer *Tracer) OnCreate(event promise.TraceEvent) {}
+
+// OnStart
+// adds a start event to the promise's span
+func (tracer *Tracer) OnStart(event promise.TraceEvent) {
+	if span, ok := tracer.span(event.ID); ok {
+		span.AddEvent("start", trace.WithTimestamp(event.Time))
+	}
+}
+
+// OnSubscribe
+// adds a subscribe event to the promise's span
+func (tracer *Tracer) OnSubscribe(event promise.TraceEvent) {
+	if span, ok := tracer.span(event.ID); ok {
+		span.AddEvent("subscribe",
+			trace.WithTimestamp(event.Time),
+			trace.WithAttributes(attribute.String("promise.subscriber", event.Subscriber)),
+		)
+	}
+}
+
+// OnSettle
+// records the promise's error
+// and ends its span
+func (tracer *Tracer) OnSettle(event promise.TraceEvent) {
+	tracer.mutex.Lock()
+	span, ok := tracer.spans[event.ID]
+	delete(tracer.spans, event.ID)
+	tracer.mutex.Unlock()
+	if !ok {
+		return
+	}
+	if event.Err != nil {
+		span.RecordError(event.Err, trace.WithTimestamp(event.Time))
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+	span.End(trace.WithTimestamp(event.Time))
+}
+
+// OnPanic
+// adds a panic event to the promise's span.
+// A subscriber panics after the promise's span
+// ended, so it gets a span of its own
+func (tracer *Tracer) OnPanic(event promise.TraceEvent) {
+	span, ok := tracer.span(event.ID)
+	if !ok {
+		_, span = tracer.tracer.Start(event.Context, "promise."+event.Subscriber,
+			trace.WithTimestamp(event.Time),
+			trace.WithAttributes(attributes(event)...),
+		)
+		defer span.End(trace.WithTimestamp(event.Time))
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+	span.AddEvent("panic",
+		trace.WithTimestamp(event.Time),
+		trace.WithAttributes(
+			attribute.String("promise.panic", fmt.Sprint(event.Panic)),
+			attribute.String("promise.subscriber", event.Subscriber),
+		),
+	)
+}
@@ -0,0 +1,126 @@
+package otelpromise
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	promise "github.com/Shehats/go-promisify"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func useTracer(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := promise.SetTracer(NewTracer(provider))
+	t.Cleanup(func() {
+		promise.SetTracer(previous)
+	})
+	return provider, exporter
+}
+
+func spanOf(t *testing.T, spans tracetest.SpanStubs, id uint64) tracetest.SpanStub {
+	for _, span := range spans {
+		for _, attr := range span.Attributes {
+			if attr.Key == "promise.id" && attr.Value.AsInt64() == int64(id) {
+				return span
+			}
+		}
+	}
+	t.Fatalf("Promise %d has no span", id)
+	return tracetest.SpanStub{}
+}
+
+func eventNames(span tracetest.SpanStub) []string {
+	names := make([]string, 0)
+	for _, event := range span.Events {
+		names = append(names, event.Name)
+	}
+	return names
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("Creates a child span per step", func(t *testing.T) {
+		provider, exporter := useTracer(t)
+		ctx, root := provider.Tracer("test").Start(context.Background(), "root")
+		p := promise.PromisifyContext[string](ctx, func(ctx context.Context) (string, error) {
+			return "Someone famous", nil
+		})
+		p1 := promise.Then(p, func(name string) (int, error) {
+			return len(name), nil
+		})
+		p1.Await()
+		root.End()
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 3)
+		span := spanOf(t, spans, p.ID())
+		span1 := spanOf(t, spans, p1.ID())
+		assert.Equal(t, span.Name, "promise.context")
+		assert.Equal(t, span.Parent.SpanID(), root.SpanContext().SpanID())
+		assert.Equal(t, span1.Name, "promise.then")
+		assert.Equal(t, span1.Parent.SpanID(), span.SpanContext.SpanID())
+		assert.Equal(t, span1.SpanContext.TraceID(), root.SpanContext().TraceID())
+		assert.Contains(t, span1.Attributes, attribute.Int64("promise.parent_id", int64(p.ID())))
+		assert.Contains(t, eventNames(span), "start")
+		assert.Contains(t, eventNames(span), "subscribe")
+		assert.Equal(t, span1.Status.Code, codes.Unset)
+	})
+	t.Run("Passes the span to the promisified function", func(t *testing.T) {
+		_, exporter := useTracer(t)
+		p := promise.Promisify[string]("Someone famous")
+		p1 := promise.Then(p, func(name string) (string, error) {
+			return name, nil
+		})
+		p1.Await()
+		p2 := promise.PromisifyContext[string](p1.Context(), func(ctx context.Context) (string, error) {
+			return "Stunt Double", nil
+		})
+		p2.Await()
+
+		spans := exporter.GetSpans()
+		span1 := spanOf(t, spans, p1.ID())
+		span2 := spanOf(t, spans, p2.ID())
+		assert.Equal(t, span2.Parent.SpanID(), span1.SpanContext.SpanID())
+	})
+	t.Run("Records errors and panics", func(t *testing.T) {
+		_, exporter := useTracer(t)
+		p := promise.Promisify[string](func() (string, error) {
+			return "", errors.New("Famous people don't shake hands")
+		})
+		p1 := promise.Catch(p, func(err error) (string, error) {
+			panic("Stunt Double")
+		})
+		p1.Catch(func(error) {})
+		p1.Exec()
+		p2 := promise.Promisify[string]("Someone famous")
+		p2.Then(func(string) {
+			panic("Stunt Double")
+		})
+		p2.Catch(func(error) {})
+		p2.Exec()
+
+		spans := exporter.GetSpans()
+		span := spanOf(t, spans, p.ID())
+		assert.Equal(t, span.Status, sdktrace.Status{Code: codes.Error, Description: "Famous people don't shake hands"})
+		assert.Contains(t, eventNames(span), "exception")
+
+		span1 := spanOf(t, spans, p1.ID())
+		assert.Equal(t, span1.Status.Code, codes.Error)
+		assert.Contains(t, eventNames(span1), "panic")
+
+		subscriber := 0
+		for _, span := range spans {
+			if span.Name == "promise.then" && span.Status.Code == codes.Error {
+				assert.Equal(t, eventNames(span), []string{"panic"})
+				assert.Equal(t, span.Parent.SpanID(), spanOf(t, spans, p2.ID()).SpanContext.SpanID())
+				subscriber++
+			}
+		}
+		assert.Equal(t, subscriber, 1)
+	})
+}
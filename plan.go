@@ -0,0 +1,132 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// callPlans caches a callPlan per
+// function type so the reflection work
+// is only done once per signature
+var callPlans sync.Map
+
+// errorType is the reflect type of error
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// callPlan stores the parameters
+// and return types of a function
+type callPlan struct {
+	// in are the function's parameter types
+	in []reflect.Type
+	// out are the function's return types
+	out []reflect.Type
+	// variadic is true if the last parameter
+	// is variadic
+	variadic bool
+}
+
+// Invoker calls a prepared function
+// and returns a promise of its result
+type Invoker[T any] struct {
+	function reflect.Value
+	plan     *callPlan
+}
+
+// planOf
+// returns the cached call plan of a
+// function type and compiles it if it
+// doesn't exist yet
+func planOf(functionType reflect.Type) *callPlan {
+	if plan, ok := callPlans.Load(functionType); ok {
+		return plan.(*callPlan)
+	}
+	plan := &callPlan{
+		in:       make([]reflect.Type, functionType.NumIn()),
+		out:      make([]reflect.Type, functionType.NumOut()),
+		variadic: functionType.IsVariadic(),
+	}
+	for i := range plan.in {
+		plan.in[i] = functionType.In(i)
+	}
+	for i := range plan.out {
+		plan.out[i] = functionType.Out(i)
+	}
+	actual, _ := callPlans.LoadOrStore(functionType, plan)
+	return actual.(*callPlan)
+}
+
+// argType
+// returns the type of the i-th argument
+// or nil if the function doesn't take it
+func (plan *callPlan) argType(i int) reflect.Type {
+	last := len(plan.in) - 1
+	if plan.variadic && i >= last {
+		return plan.in[last].Elem()
+	}
+	if i > last {
+		return nil
+	}
+	return plan.in[i]
+}
+
+// values
+// converts the arguments to reflect values
+// using the plan's parameter types.
+// nil arguments are converted to the zero
+// value of their parameter type
+func (plan *callPlan) values(args []any) []reflect.Value {
+	values := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		values[i] = reflect.ValueOf(arg)
+		if arg == nil {
+			if argType := plan.argType(i); argType != nil {
+				values[i] = reflect.Zero(argType)
+			}
+		}
+	}
+	return values
+}
+
+// validate
+// checks that the function returns (T, error)
+func (plan *callPlan) validate(resultType reflect.Type) error {
+	if len(plan.out) != 2 || plan.out[1] != errorType {
+		return fmt.Errorf("Promise function has to return (%v, error)", resultType)
+	}
+	if !plan.out[0].AssignableTo(resultType) {
+		return fmt.Errorf("Promise function returns %v which can't be used as %v", plan.out[0], resultType)
+	}
+	return nil
+}
+
+// Prepare
+// checks the signature of a function once
+// and returns an Invoker that creates promises
+// from it without inspecting the function again.
+// Ideal for hot paths that promisify the same
+// function many times.
+// Panics if fn isn't a function that returns (T, error)
+func Prepare[T any](fn any) *Invoker[T] {
+	if fn == nil || !isFunction(fn) {
+		panic(fmt.Sprintf("Promise can't prepare %T as it isn't a function", fn))
+	}
+	function := reflect.ValueOf(fn)
+	plan := planOf(function.Type())
+	if err := plan.validate(reflect.TypeOf((*T)(nil)).Elem()); err != nil {
+		panic(err.Error())
+	}
+	return &Invoker[T]{
+		function: function,
+		plan:     plan,
+	}
+}
+
+// Call
+// runs the prepared function with the arguments
+// and returns a promise of its result like
+// Promisify, without looking up the function's
+// type or call plan
+func (invoker *Invoker[T]) Call(args ...any) *Promise[T] {
+	return promisifyFunc(funcRunner[T], invoker.function, invoker.plan.values(args)...)
+}
@@ -0,0 +1,104 @@
+package promise
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMessage(name string, subject string) (testMessage, error) {
+	return testMessage{
+		Name:    name,
+		Subject: subject,
+	}, nil
+}
+
+func TestCallPlan(t *testing.T) {
+	t.Run("Caches the plan per function type", func(t *testing.T) {
+		functionType := reflect.TypeOf(newTestMessage)
+		assert.Same(t, planOf(functionType), planOf(functionType))
+	})
+	t.Run("Converts nil arguments to zero values", func(t *testing.T) {
+		p := Promisify[int](func(names []string, err error) (int, error) {
+			return len(names), err
+		}, nil, nil)
+		count, err := p.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, count, 0)
+	})
+	t.Run("Fulfills with the zero value of a nil interface result", func(t *testing.T) {
+		p := Promisify[io.Reader](func() (io.Reader, error) {
+			return nil, nil
+		})
+		reader, err := p.Await()
+		assert.NoError(t, err)
+		assert.Nil(t, reader)
+	})
+	t.Run("Passes variadic arguments", func(t *testing.T) {
+		p := Promisify[int](func(prefix string, names ...string) (int, error) {
+			return len(names), nil
+		}, "names", "John Doe", "Jane Doe")
+		count, err := p.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, count, 2)
+	})
+}
+
+func TestPrepare(t *testing.T) {
+	t.Run("Creates promises from a prepared function", func(t *testing.T) {
+		newMessage := Prepare[testMessage](newTestMessage)
+		for i := 0; i < 3; i++ {
+			msg, err := newMessage.Call("Someone famous", fmt.Sprintf("Hi #%d", i)).Await()
+			assert.NoError(t, err)
+			assert.Equal(t, msg, testMessage{
+				Name:    "Someone famous",
+				Subject: fmt.Sprintf("Hi #%d", i),
+			})
+		}
+	})
+	t.Run("Rejects when the prepared function fails", func(t *testing.T) {
+		fail := Prepare[testMessage](func() (testMessage, error) {
+			return testMessage{}, fmt.Errorf("Famous people don't shake hands")
+		})
+		_, err := fail.Call().Await()
+		assert.EqualError(t, err, "Famous people don't shake hands")
+	})
+	t.Run("Fulfills with the zero value of a nil interface result", func(t *testing.T) {
+		open := Prepare[io.Reader](func() (io.Reader, error) {
+			return nil, nil
+		})
+		reader, err := open.Call().Await()
+		assert.NoError(t, err)
+		assert.Nil(t, reader)
+	})
+	t.Run("Panics if the function has the wrong signature", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Prepare[testMessage](func() testMessage { return testMessage{} })
+		})
+		assert.Panics(t, func() {
+			Prepare[string](newTestMessage)
+		})
+		assert.Panics(t, func() {
+			Prepare[string]("not a function")
+		})
+	})
+}
+
+func BenchmarkPromisify(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Promisify[testMessage](newTestMessage, "Someone famous", "Hi famous person").Await()
+	}
+}
+
+func BenchmarkPrepare(b *testing.B) {
+	newMessage := Prepare[testMessage](newTestMessage)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newMessage.Call("Someone famous", "Hi famous person").Await()
+	}
+}
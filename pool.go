@@ -0,0 +1,234 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrPoolClosed is the error the promises of a
+	// Pool are rejected with when they're submitted
+	// after it was shut down, or when it's stopped
+	// while they're queued
+	ErrPoolClosed = errors.New("Pool is closed")
+	// ErrPoolFull is the error the promises of
+	// a Pool are rejected with when its
+	// queue is full
+	ErrPoolFull = errors.New("Pool queue is full")
+)
+
+// PoolStats counts the
+// tasks of a Pool
+type PoolStats struct {
+	// Workers is the number of workers
+	Workers int
+	// Busy is the number of workers
+	// that run a task
+	Busy int
+	// Queued is the number of tasks
+	// waiting for a worker
+	Queued int
+	// Submitted is the number of
+	// tasks that were queued
+	Submitted uint64
+	// Completed is the number of
+	// tasks the workers ran
+	Completed uint64
+	// Rejected is the number of tasks
+	// that were rejected because the pool
+	// was full or closed
+	Rejected uint64
+	// Skipped is the number of queued
+	// tasks the workers didn't run
+	// because they were cancelled
+	Skipped uint64
+}
+
+// Pool runs the tasks submitted to it on
+// a fixed number of workers, the tasks wait
+// for a worker in a bounded queue.
+// It's safe to use from any go routine
+type Pool struct {
+	mutex  sync.Mutex
+	tasks  chan *poolTask
+	closed bool
+	// stopped is true once Stop was called,
+	// the queued tasks are rejected instead
+	// of run
+	stopped bool
+	// workers waits for the
+	// workers to exit
+	workers sync.WaitGroup
+	stats   PoolStats
+}
+
+// poolTask is a task
+// waiting for a worker
+type poolTask struct {
+	// run returns false if the task
+	// was cancelled and didn't run
+	run    func() bool
+	reject func(err error)
+}
+
+// NewPool
+// Creates a Pool with workers workers
+// and room for queueSize tasks waiting
+// for them
+func NewPool(workers int, queueSize int) *Pool {
+	if workers <= 0 {
+		panic("Pool workers have to be positive")
+	}
+	pool := &Pool{
+		tasks: make(chan *poolTask, queueSize),
+		stats: PoolStats{Workers: workers},
+	}
+	pool.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+	return pool
+}
+
+// Submit
+// queues fn to run on a worker of the pool
+// and returns a promise that settles with its
+// result. The promise is rejected with ErrPoolFull
+// if the queue is full and with ErrPoolClosed if
+// the pool is closed. A queued task that is
+// cancelled doesn't run
+func Submit[T any](pool *Pool, fn func() (T, error)) *Promise[T] {
+	deferred := NewDeferred[T]()
+	task := &poolTask{
+		run: func() bool {
+			if deferred.Settled() {
+				return false
+			}
+			defer func() {
+				if r := recover(); r != nil {
+					deferred.Reject(deferred.promise.panicError(r, ""))
+				}
+			}()
+			obj, err := fn()
+			if err != nil {
+				deferred.Reject(err)
+			} else {
+				deferred.Resolve(obj)
+			}
+			return true
+		},
+		reject: func(err error) {
+			deferred.Reject(err)
+		},
+	}
+	if err := pool.submit(task); err != nil {
+		deferred.Reject(err)
+	}
+	return deferred.promise
+}
+
+// Shutdown
+// stops the pool from accepting tasks and waits
+// until the workers ran the queued tasks, or
+// returns the error of ctx if it's done first
+func (pool *Pool) Shutdown(ctx context.Context) error {
+	pool.close()
+	done := make(chan struct{})
+	go func() {
+		pool.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop
+// stops the pool from accepting tasks and
+// rejects the queued ones with ErrPoolClosed
+// without waiting for the running ones
+func (pool *Pool) Stop() {
+	pool.mutex.Lock()
+	pool.stopped = true
+	pool.mutex.Unlock()
+	pool.close()
+	for task := range pool.tasks {
+		pool.mutex.Lock()
+		pool.stats.Rejected++
+		pool.mutex.Unlock()
+		task.reject(ErrPoolClosed)
+	}
+}
+
+// Stats
+// returns the counts of the tasks
+func (pool *Pool) Stats() PoolStats {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	stats := pool.stats
+	stats.Queued = len(pool.tasks)
+	return stats
+}
+
+// submit
+// queues task unless the pool
+// is closed or full
+func (pool *Pool) submit(task *poolTask) error {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	if pool.closed {
+		pool.stats.Rejected++
+		return ErrPoolClosed
+	}
+	select {
+	case pool.tasks <- task:
+		pool.stats.Submitted++
+		return nil
+	default:
+		pool.stats.Rejected++
+		return ErrPoolFull
+	}
+}
+
+// close
+// stops the pool from accepting tasks,
+// the workers exit once the queue is empty
+func (pool *Pool) close() {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	if !pool.closed {
+		pool.closed = true
+		close(pool.tasks)
+	}
+}
+
+// work
+// runs the queued tasks until the pool is
+// closed, or rejects them once it's stopped
+func (pool *Pool) work() {
+	defer pool.workers.Done()
+	for task := range pool.tasks {
+		pool.mutex.Lock()
+		if pool.stopped {
+			pool.stats.Rejected++
+			pool.mutex.Unlock()
+			task.reject(ErrPoolClosed)
+			continue
+		}
+		pool.stats.Busy++
+		pool.mutex.Unlock()
+		ran := task.run()
+		pool.mutex.Lock()
+		pool.stats.Busy--
+		if ran {
+			pool.stats.Completed++
+		} else {
+			pool.stats.Skipped++
+		}
+		pool.mutex.Unlock()
+	}
+}
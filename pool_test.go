@@ -0,0 +1,140 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingTask returns a task that signals
+// it started and waits for release
+func blockingTask(started chan<- struct{}, release <-chan struct{}, name string) func() (string, error) {
+	return func() (string, error) {
+		started <- struct{}{}
+		<-release
+		return name, nil
+	}
+}
+
+func TestPool(t *testing.T) {
+	t.Run("Runs the tasks on the workers", func(t *testing.T) {
+		pool := NewPool(2, 2)
+		defer pool.Stop()
+		name, err := Submit(pool, func() (string, error) {
+			return "Someone famous", nil
+		}).Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Someone famous")
+
+		_, err = Submit(pool, func() (string, error) {
+			return "", errors.New("Famous people don't shake hands")
+		}).Await()
+		assert.EqualError(t, err, "Famous people don't shake hands")
+
+		_, err = Submit(pool, func() (string, error) {
+			panic("Famous people don't sign autographs")
+		}).Await()
+		assert.ErrorAs(t, err, new(*PanicError))
+	})
+	t.Run("Rejects the tasks beyond the queue", func(t *testing.T) {
+		pool := NewPool(1, 1)
+		started, release := make(chan struct{}), make(chan struct{})
+		running := Submit(pool, blockingTask(started, release, "Someone famous"))
+		<-started
+		queued := Submit(pool, func() (string, error) {
+			return "Stunt Double", nil
+		})
+		_, err := Submit(pool, func() (string, error) {
+			assert.Fail(t, "This should never get called")
+			return "", nil
+		}).Await()
+		assert.ErrorIs(t, err, ErrPoolFull)
+		assert.Equal(t, pool.Stats(), PoolStats{Workers: 1, Busy: 1, Queued: 1, Submitted: 2, Rejected: 1})
+
+		close(release)
+		name, _ := running.Await()
+		assert.Equal(t, name, "Someone famous")
+		name, _ = queued.Await()
+		assert.Equal(t, name, "Stunt Double")
+		assert.Nil(t, pool.Shutdown(context.Background()))
+		assert.Equal(t, pool.Stats(), PoolStats{Workers: 1, Submitted: 2, Completed: 2, Rejected: 1})
+	})
+	t.Run("Shuts down once the queued tasks ran", func(t *testing.T) {
+		pool := NewPool(1, 1)
+		started, release := make(chan struct{}), make(chan struct{})
+		Submit(pool, blockingTask(started, release, "Someone famous"))
+		<-started
+		queued := Submit(pool, func() (string, error) {
+			return "Stunt Double", nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, pool.Shutdown(ctx), context.Canceled)
+		_, err := Submit(pool, func() (string, error) {
+			return "", nil
+		}).Await()
+		assert.ErrorIs(t, err, ErrPoolClosed)
+
+		close(release)
+		assert.Nil(t, pool.Shutdown(context.Background()))
+		name, _ := queued.Await()
+		assert.Equal(t, name, "Stunt Double")
+	})
+	t.Run("Rejects the queued tasks when stopped", func(t *testing.T) {
+		pool := NewPool(1, 1)
+		started, release := make(chan struct{}), make(chan struct{})
+		running := Submit(pool, blockingTask(started, release, "Someone famous"))
+		<-started
+		queued := Submit(pool, func() (string, error) {
+			assert.Fail(t, "This should never get called")
+			return "", nil
+		})
+		pool.Stop()
+		_, err := queued.Await()
+		assert.ErrorIs(t, err, ErrPoolClosed)
+
+		close(release)
+		name, _ := running.Await()
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Doesn't run the queued tasks a worker takes once stopped", func(t *testing.T) {
+		pool := NewPool(1, 1)
+		started, release := make(chan struct{}), make(chan struct{})
+		running := Submit(pool, blockingTask(started, release, "Someone famous"))
+		<-started
+		queued := Submit(pool, func() (string, error) {
+			assert.Fail(t, "This should never get called")
+			return "", nil
+		})
+		// the worker takes the queued task
+		// before Stop drains the queue
+		pool.mutex.Lock()
+		pool.stopped = true
+		pool.mutex.Unlock()
+		close(release)
+		running.Await()
+		_, err := queued.Await()
+		assert.ErrorIs(t, err, ErrPoolClosed)
+		assert.Equal(t, pool.Stats().Rejected, uint64(1))
+		pool.Stop()
+	})
+	t.Run("Skips the queued tasks that were cancelled", func(t *testing.T) {
+		pool := NewPool(1, 1)
+		started, release := make(chan struct{}), make(chan struct{})
+		Submit(pool, blockingTask(started, release, "Someone famous"))
+		<-started
+		queued := Submit(pool, func() (string, error) {
+			assert.Fail(t, "This should never get called")
+			return "", nil
+		})
+		assert.True(t, queued.Cancel(nil))
+		close(release)
+		assert.Nil(t, pool.Shutdown(context.Background()))
+		stats := pool.Stats()
+		assert.Equal(t, stats.Completed, uint64(1))
+		assert.Equal(t, stats.Skipped, uint64(1))
+	})
+}
@@ -0,0 +1,206 @@
+package promise
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Priority of the steps of a promise
+// run by a PriorityExecutor
+type Priority int
+
+const (
+	// Low is the priority of background
+	// work that yields to everything else
+	Low Priority = iota
+	// Normal is the priority of the
+	// promises without a priority
+	Normal
+	// High is the priority of interactive
+	// work, like serving a user's request
+	High
+)
+
+// String
+// returns the priority's name
+func (priority Priority) String() string {
+	switch priority {
+	case Low:
+		return "low"
+	case Normal:
+		return "normal"
+	case High:
+		return "high"
+	}
+	return "unknown"
+}
+
+// priorityKey is the context key
+// of the priority
+type priorityKey struct{}
+
+// WithPriority
+// returns a copy of ctx with priority, the steps
+// of promises created with PromisifyContext from
+// it and of the promises created from them run
+// with priority
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityOf
+// returns the priority of ctx,
+// Normal if it has none
+func PriorityOf(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return priority
+	}
+	return Normal
+}
+
+// Prioritizer is an Executor that runs tasks
+// by priority, the promises pass the priority
+// of their steps to it
+type Prioritizer interface {
+	Executor
+	// ExecutePriority runs the task with
+	// priority without blocking the caller
+	ExecutePriority(task func(), priority Priority)
+}
+
+// PriorityExecutor is a Prioritizer that runs
+// the tasks on a fixed number of workers, the
+// waiting task with the highest priority first.
+// A waiting task gains a priority level every
+// time the aging interval passes so low priority
+// tasks aren't starved.
+// Steps that block on other promises need enough
+// workers for the steps they wait for
+type PriorityExecutor struct {
+	mutex sync.Mutex
+	// ready signals the workers that
+	// tasks are waiting or it's closed
+	ready *sync.Cond
+	aging time.Duration
+	// tasks waiting for a worker
+	// in the order they were queued
+	tasks  []*priorityTask
+	closed bool
+}
+
+// priorityTask is a task
+// waiting for a worker
+type priorityTask struct {
+	run      func()
+	priority Priority
+	queuedAt time.Time
+}
+
+// NewPriorityExecutor
+// Creates a PriorityExecutor with workers
+// workers that raises the priority of
+// waiting tasks every aging, tasks don't
+// age if it's zero
+func NewPriorityExecutor(workers int, aging time.Duration) *PriorityExecutor {
+	if workers <= 0 {
+		panic("PriorityExecutor workers have to be positive")
+	}
+	executor := &PriorityExecutor{aging: aging}
+	executor.ready = sync.NewCond(&executor.mutex)
+	for i := 0; i < workers; i++ {
+		go executor.work()
+	}
+	return executor
+}
+
+// Execute
+// runs the task with Normal priority
+func (executor *PriorityExecutor) Execute(task func()) {
+	executor.ExecutePriority(task, Normal)
+}
+
+// ExecutePriority
+// queues the task until a worker runs it,
+// the task runs in its own go routine once
+// the executor is closed
+func (executor *PriorityExecutor) ExecutePriority(task func(), priority Priority) {
+	executor.mutex.Lock()
+	if executor.closed {
+		executor.mutex.Unlock()
+		go task()
+		return
+	}
+	executor.tasks = append(executor.tasks, &priorityTask{
+		run:      task,
+		priority: priority,
+		queuedAt: currentClock().Now(),
+	})
+	executor.mutex.Unlock()
+	executor.ready.Signal()
+}
+
+// Pending
+// returns the number of tasks
+// waiting for a worker
+func (executor *PriorityExecutor) Pending() int {
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+	return len(executor.tasks)
+}
+
+// Close
+// stops the workers once the waiting
+// tasks ran
+func (executor *PriorityExecutor) Close() {
+	executor.mutex.Lock()
+	executor.closed = true
+	executor.mutex.Unlock()
+	executor.ready.Broadcast()
+}
+
+// work
+// runs the waiting tasks until
+// the executor is closed
+func (executor *PriorityExecutor) work() {
+	for {
+		executor.mutex.Lock()
+		for len(executor.tasks) == 0 && !executor.closed {
+			executor.ready.Wait()
+		}
+		if len(executor.tasks) == 0 {
+			executor.mutex.Unlock()
+			return
+		}
+		task := executor.next()
+		executor.mutex.Unlock()
+		task.run()
+	}
+}
+
+// next
+// removes and returns the oldest task with the
+// highest aged priority, the caller
+// holds the mutex
+func (executor *PriorityExecutor) next() *priorityTask {
+	now := currentClock().Now()
+	best := 0
+	for i, task := range executor.tasks {
+		if executor.aged(task, now) > executor.aged(executor.tasks[best], now) {
+			best = i
+		}
+	}
+	task := executor.tasks[best]
+	executor.tasks = append(executor.tasks[:best], executor.tasks[best+1:]...)
+	return task
+}
+
+// aged
+// returns the priority of task raised by
+// a level for every aging it waited
+func (executor *PriorityExecutor) aged(task *priorityTask, now time.Time) Priority {
+	if executor.aging <= 0 {
+		return task.priority
+	}
+	return task.priority + Priority(now.Sub(task.queuedAt)/executor.aging)
+}
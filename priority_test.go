@@ -0,0 +1,107 @@
+package promise
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordedTasks records the order
+// the tasks of an executor ran in
+type recordedTasks struct {
+	mutex sync.Mutex
+	names []string
+	done  sync.WaitGroup
+}
+
+func (r *recordedTasks) task(name string) func() {
+	r.done.Add(1)
+	return func() {
+		defer r.done.Done()
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.names = append(r.names, name)
+	}
+}
+
+// blockedExecutor returns an executor with one worker
+// that's busy until release is closed
+func blockedExecutor(t *testing.T, aging time.Duration) (*PriorityExecutor, chan struct{}) {
+	executor := NewPriorityExecutor(1, aging)
+	t.Cleanup(executor.Close)
+	started, release := make(chan struct{}), make(chan struct{})
+	executor.Execute(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	return executor, release
+}
+
+func TestPriorityExecutor(t *testing.T) {
+	t.Run("Runs the tasks with the highest priority first", func(t *testing.T) {
+		useTestClock(t)
+		executor, release := blockedExecutor(t, 0)
+		recorded := &recordedTasks{}
+		executor.ExecutePriority(recorded.task("reindex"), Low)
+		executor.Execute(recorded.task("report"))
+		executor.ExecutePriority(recorded.task("first request"), High)
+		executor.ExecutePriority(recorded.task("second request"), High)
+		assert.Equal(t, executor.Pending(), 4)
+		close(release)
+		recorded.done.Wait()
+		assert.Equal(t, recorded.names, []string{"first request", "second request", "report", "reindex"})
+	})
+	t.Run("Ages the waiting tasks", func(t *testing.T) {
+		clock := useTestClock(t)
+		executor, release := blockedExecutor(t, time.Second)
+		recorded := &recordedTasks{}
+		executor.ExecutePriority(recorded.task("reindex"), Low)
+		clock.Advance(2 * time.Second)
+		executor.ExecutePriority(recorded.task("request"), High)
+		executor.Execute(recorded.task("report"))
+		close(release)
+		recorded.done.Wait()
+		assert.Equal(t, recorded.names, []string{"reindex", "request", "report"})
+	})
+	t.Run("Runs the steps of promises with their priority", func(t *testing.T) {
+		useTestClock(t)
+		executor, release := blockedExecutor(t, 0)
+		previous := SetExecutor(executor)
+		defer SetExecutor(previous)
+		recorded := &recordedTasks{}
+		step := func(name string) func(context.Context) (string, error) {
+			task := recorded.task(name)
+			return func(context.Context) (string, error) {
+				task()
+				return name, nil
+			}
+		}
+		reindex := PromisifyContext[string](WithPriority(context.Background(), Low), step("reindex"))
+		request := PromisifyContext[string](WithPriority(context.Background(), High), step("request"))
+		close(release)
+		recorded.done.Wait()
+		assert.Equal(t, recorded.names, []string{"request", "reindex"})
+		for _, p := range []*Promise[string]{reindex, request} {
+			_, err := p.Await()
+			assert.Nil(t, err)
+		}
+	})
+	t.Run("Runs the tasks in their own go routine once closed", func(t *testing.T) {
+		executor := NewPriorityExecutor(1, 0)
+		executor.Close()
+		recorded := &recordedTasks{}
+		executor.Execute(recorded.task("report"))
+		recorded.done.Wait()
+		assert.Equal(t, recorded.names, []string{"report"})
+	})
+}
+
+func TestPriorityOf(t *testing.T) {
+	assert.Equal(t, PriorityOf(context.Background()), Normal)
+	assert.Equal(t, PriorityOf(WithPriority(context.Background(), High)), High)
+	assert.Equal(t, High.String(), "high")
+}
@@ -0,0 +1,45 @@
+package promise
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+	"sync/atomic"
+)
+
+// profileLabels is true while the steps of
+// the promises run with pprof labels
+var profileLabels atomic.Bool
+
+// SetProfileLabels
+// makes the steps of the promises run with
+// the runtime/pprof labels promise_chain,
+// promise_step and promise_name, on top of the
+// labels of the promise's context, and returns
+// whether they did before.
+// Ideal for filtering CPU and goroutine
+// profiles per logical operation
+func SetProfileLabels(enabled bool) bool {
+	return profileLabels.Swap(enabled)
+}
+
+// labelled
+// wraps the step f of the promise so it runs
+// with its pprof labels when they're enabled
+func (promise *Promise[T]) labelled(step string, f func()) func() {
+	if !profileLabels.Load() {
+		return f
+	}
+	return func() {
+		labels := []string{
+			"promise_chain", strconv.FormatUint(promise.chainID, 10),
+			"promise_step", step,
+		}
+		if name := promise.Name(); name != "" {
+			labels = append(labels, "promise_name", name)
+		}
+		pprof.Do(promise.ctx, pprof.Labels(labels...), func(context.Context) {
+			f()
+		})
+	}
+}
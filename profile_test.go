@@ -0,0 +1,63 @@
+package promise
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func useProfileLabels(t *testing.T) {
+	previous := SetProfileLabels(true)
+	t.Cleanup(func() {
+		SetProfileLabels(previous)
+	})
+}
+
+// goroutineLabels
+// returns the goroutine profile
+// with the goroutines' labels
+func goroutineLabels() string {
+	var buffer bytes.Buffer
+	pprof.Lookup("goroutine").WriteTo(&buffer, 1)
+	return buffer.String()
+}
+
+func TestProfileLabels(t *testing.T) {
+	t.Run("Runs the steps with the promise's labels", func(t *testing.T) {
+		useProfileLabels(t)
+		ctx := pprof.WithLabels(context.Background(), pprof.Labels("request", "42"))
+		profiles := make(chan string, 2)
+		p := PromisifyContext[string](ctx, func(ctx context.Context) (string, error) {
+			profiles <- goroutineLabels()
+			return "Someone famous", nil
+		}).Named("fetch-users")
+		p1 := Then(p, func(name string) (string, error) {
+			profiles <- goroutineLabels()
+			return name, nil
+		})
+		p1.Await()
+
+		chain := fmt.Sprintf(`"promise_chain":"%d"`, p.ID())
+		profile := <-profiles
+		assert.Contains(t, profile, chain)
+		assert.Contains(t, profile, `"promise_step":"context"`)
+		assert.Contains(t, profile, `"request":"42"`)
+		profile = <-profiles
+		assert.Contains(t, profile, chain)
+		assert.Contains(t, profile, `"promise_step":"then"`)
+		assert.Contains(t, profile, `"promise_name":"fetch-users"`)
+		assert.Contains(t, profile, `"request":"42"`)
+	})
+	t.Run("Runs the steps without labels when disabled", func(t *testing.T) {
+		SetProfileLabels(false)
+		p := Promisify[string](func() (string, error) {
+			return goroutineLabels(), nil
+		})
+		profile, _ := p.Await()
+		assert.NotContains(t, profile, fmt.Sprintf(`"promise_chain":"%d"`, p.ID()))
+	})
+}
@@ -1,78 +1,145 @@
 package promise
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Promise interface that defines
 // methods that are similar to those
 // of Javascript
 type Promise[T any] struct {
-	// successChannel is were the retun
-	// of the promise is stored
-	successChannel chan T
-	// failureChannel is were the error
-	// of the primise is stored
-	failureChannel chan error
-	// mutex that ensures that the promises
+	// id identifies the promise
+	id uint64
+	// parentID is the id of the promise this
+	// promise was created from, 0 if it has none
+	parentID uint64
+	// chainID is the id of the first
+	// promise of the promise's chain
+	chainID uint64
+	// step is the kind of step that
+	// settles the promise
+	step string
+	// obj is the value the promise
+	// was fulfilled with
+	obj T
+	// err is the error the promise
+	// was rejected with
+	err error
+	// resultMutex guards obj and err
+	resultMutex *sync.RWMutex
+	// settled is true once the promise
+	// has its result, a promise is only
+	// settled once
+	settled *atomic.Bool
+	// handled is true once the promise's
+	// error was passed to a subscriber
+	handled *atomic.Bool
+	// queue that ensures that the promises
 	// are executed in order
-	mutex *sync.Mutex
+	queue *queue
 	// promise's wait group
-	wg *sync.WaitGroup
-	// boolean's to manage channels
-	hasThenSubscriber    *atomic.Bool
-	hasCatchSubscriber   *atomic.Bool
-	hasFinallySubscriber *atomic.Bool
-	hasExecSubscriber    *atomic.Bool
-	thenExecuted         *atomic.Bool
-	catchExecuted        *atomic.Bool
-	awaitSubscriber      *atomic.Bool
+	wg *waitGroup
+	// lazy is set when the promise only starts
+	// executing once it's subscribed to
+	lazy *lazy
+	// cancellation of the promise
+	cancellation *cancellation
+	// tracked is true if the promise is
+	// tracked by the leak tracker
+	tracked bool
+	// tracer is notified about the
+	// promise's lifecycle
+	tracer Tracer
+	// ctx is the context of the promise,
+	// inherited by the promises created from it
+	ctx context.Context
+	// metrics counts the promise
+	metrics Metrics
+	// naming holds the promise's name
+	naming *naming
+	// createdAt is when the promise was created
+	createdAt time.Time
+	// settledAt is when the promise was
+	// settled, guarded by resultMutex
+	settledAt time.Time
+	// logger logs the promise's failures
+	logger *slog.Logger
+	// origin is where the promise was
+	// created, only in debug mode
+	origin *origin
 }
 
-// stores information about any
-// object
-type meta struct {
-	objectType  reflect.Type
-	objectValue reflect.Value
-}
+// promiseIDs generates the promises' ids
+var promiseIDs atomic.Uint64
 
 // newPromise
 // Creates a new Promise instance
-func newPromise[T any]() *Promise[T] {
-	return &Promise[T]{
-		successChannel:       make(chan T, 1),
-		failureChannel:       make(chan error, 1),
-		mutex:                &sync.Mutex{},
-		wg:                   &sync.WaitGroup{},
-		hasThenSubscriber:    &atomic.Bool{},
-		hasCatchSubscriber:   &atomic.Bool{},
-		hasFinallySubscriber: &atomic.Bool{},
-		hasExecSubscriber:    &atomic.Bool{},
-		thenExecuted:         &atomic.Bool{},
-		catchExecuted:        &atomic.Bool{},
-		awaitSubscriber:      &atomic.Bool{},
+// with ctx that is settled by step
+func newPromise[T any](ctx context.Context, step string) *Promise[T] {
+	id := promiseIDs.Add(1)
+	promise := &Promise[T]{
+		id:           id,
+		chainID:      id,
+		step:         step,
+		resultMutex:  &sync.RWMutex{},
+		settled:      &atomic.Bool{},
+		handled:      &atomic.Bool{},
+		queue:        newQueue(PriorityOf(ctx)),
+		wg:           &waitGroup{},
+		cancellation: &cancellation{},
+		tracer:       currentTracer(),
+		ctx:          ctx,
+		metrics:      currentMetrics(),
+		naming:       &naming{},
+		createdAt:    currentClock().Now(),
+		logger:       currentLogger(),
+		origin:       newOrigin(id, nil),
 	}
+	promise.startTask()
+	promise.created()
+	return promise
 }
 
 // fromPromise
 // Creates a promise from Promise
-func fromPromise[T, S any](promise *Promise[T]) *Promise[S] {
-	return &Promise[S]{
-		successChannel:       make(chan S, 1),
-		failureChannel:       make(chan error, 1),
-		mutex:                promise.mutex,
-		wg:                   promise.wg,
-		hasThenSubscriber:    &atomic.Bool{},
-		hasCatchSubscriber:   &atomic.Bool{},
-		hasFinallySubscriber: &atomic.Bool{},
-		hasExecSubscriber:    &atomic.Bool{},
-		thenExecuted:         &atomic.Bool{},
-		catchExecuted:        &atomic.Bool{},
-		awaitSubscriber:      &atomic.Bool{},
+// that is settled by step, the caller
+// enqueues the step
+func fromPromise[T, S any](promise *Promise[T], step string) *Promise[S] {
+	resultPromise := &Promise[S]{
+		id:          promiseIDs.Add(1),
+		parentID:    promise.id,
+		chainID:     promise.chainID,
+		step:        step,
+		resultMutex: &sync.RWMutex{},
+		settled:     &atomic.Bool{},
+		handled:     &atomic.Bool{},
+		queue:       promise.queue,
+		wg:          promise.wg,
+		cancellation: &cancellation{
+			parent:   promise,
+			upstream: promise.cancellation.upstream,
+		},
+		tracer:    promise.tracer,
+		ctx:       promise.ctx,
+		metrics:   promise.metrics,
+		naming:    &naming{name: promise.Name()},
+		createdAt: currentClock().Now(),
+		logger:    promise.logger,
 	}
+	resultPromise.origin = newOrigin(resultPromise.id, promise.origin)
+	resultPromise.created()
+	// the step is counted before the promise
+	// can be cancelled with its parent
+	resultPromise.wg.Add(1)
+	promise.cancellation.derive(weakly(resultPromise))
+	return resultPromise
 }
 
 // isFunction
@@ -83,13 +150,8 @@ func isFunction(obj any) bool {
 
 // call
 // calls any function by reflection
-func call(obj any, args ...meta) (any, error) {
-	vargs := make([]reflect.Value, 0)
-	for _, arg := range args {
-		vargs = append(vargs, arg.objectValue)
-	}
-	function := reflect.ValueOf(obj)
-	ret := function.Call(vargs)
+func call(function reflect.Value, args ...reflect.Value) (any, error) {
+	ret := function.Call(args)
 	if !ret[1].IsNil() {
 		err := ret[1].Interface().(error)
 		return ret[0].Interface(), err
@@ -105,29 +167,26 @@ func call(obj any, args ...meta) (any, error) {
 // in the subsequent promise
 func execute[T any](
 	promise *Promise[T],
-	f func(any, ...meta) (T, error),
-	obj any,
-	args ...meta) {
+	f func(reflect.Value, ...reflect.Value) (T, error),
+	function reflect.Value,
+	args ...reflect.Value) {
+	defer promise.cancellation.finish()
 	defer promise.recover()
-	defer promise.mutex.Unlock()
-	defer promise.wg.Done()
-	obj, err := f(obj, args...)
-	if err == nil {
-		promise.successChannel <- obj.(T)
-		promise.failureChannel <- nil
-	} else {
-		promise.failureChannel <- err
+	if !promise.begin() {
+		return
 	}
+	obj, err := f(function, args...)
+	promise.settle(obj, err)
 }
 
 // executeObj
-// locks the mutex while the promise is
+// holds the queue while the promise is
 // created from an object
 func executeObj[T any](promise *Promise[T], obj T) {
-	defer promise.mutex.Unlock()
-	defer promise.wg.Done()
-	promise.failureChannel <- nil
-	promise.successChannel <- obj
+	defer promise.cancellation.finish()
+	if promise.begin() {
+		promise.settle(obj, nil)
+	}
 }
 
 // executeThenCallback
@@ -137,19 +196,21 @@ func executeThenCallback[T, S any](
 	promise2 *Promise[S],
 	f func(T) (S, error),
 ) {
+	defer promise1.cancellation.onFinish(promise2.cancellation.finish)
 	defer promise2.recover()
-	defer promise2.mutex.Unlock()
-	defer promise2.wg.Done()
-	defer promise1.thenExecuted.Store(true)
-	defer promise1.clearChannelsAfterThen()
-	err := <-promise1.failureChannel
+	arg, err := promise1.result()
+	if err != nil {
+		promise1.handled.Store(true)
+	}
+	if !promise2.begin() {
+		return
+	}
 	if err == nil {
-		arg := <-promise1.successChannel
 		obj, err := f(arg)
-		promise2.successChannel <- obj
-		promise2.failureChannel <- err
+		promise2.settle(obj, err)
 	} else {
-		promise2.failureChannel <- err
+		var obj S
+		promise2.settle(obj, err)
 	}
 }
 
@@ -160,18 +221,40 @@ func executeCatchCallback[T, S any](
 	promise2 *Promise[S],
 	f func(error) (S, error),
 ) {
+	defer promise1.cancellation.onFinish(promise2.cancellation.finish)
 	defer promise2.recover()
-	defer promise2.mutex.Unlock()
-	defer promise2.wg.Done()
-	defer promise1.catchExecuted.Store(true)
-	defer promise1.clearChannelAfterCatch()
-	err := <-promise1.failureChannel
+	_, err := promise1.result()
+	if err != nil {
+		promise1.handled.Store(true)
+	}
+	if !promise2.begin() {
+		return
+	}
 	if err != nil {
 		obj, err := f(err)
-		promise2.successChannel <- obj
-		promise2.failureChannel <- err
+		promise2.settle(obj, err)
 	} else {
-		promise2.failureChannel <- nil
+		var obj S
+		promise2.settle(obj, nil)
+	}
+}
+
+// executeTap
+// executes tap using two promises
+func executeTap[T any](
+	promise1 *Promise[T],
+	promise2 *Promise[T],
+	f func(T, error),
+) {
+	defer promise1.cancellation.onFinish(promise2.cancellation.finish)
+	defer promise2.recover()
+	obj, err := promise1.result()
+	if err != nil {
+		promise1.handled.Store(true)
+	}
+	f(obj, err)
+	if promise2.begin() {
+		promise2.settle(obj, err)
 	}
 }
 
@@ -181,9 +264,9 @@ func executeFinally[T any](
 	promise *Promise[T],
 	f func(),
 ) {
-	defer promise.mutex.Unlock()
+	defer promise.queue.release()
 	defer promise.wg.Done()
-	promise.drainChannels()
+	promise.ensureHandled()
 	f()
 }
 
@@ -192,19 +275,14 @@ func executeFinally[T any](
 func executeThen[T any](
 	promise *Promise[T],
 	f func(T),
+	attached string,
 ) {
-	defer promise.recover()
-	defer promise.mutex.Unlock()
+	defer promise.queue.release()
 	defer promise.wg.Done()
-	defer promise.thenExecuted.Store(true)
-	defer promise.clearChannelsAfterThen()
-	err := <-promise.failureChannel
+	defer promise.recoverSubscriber(stepThen, attached)
+	obj, err := promise.result()
 	if err == nil {
-		obj := <-promise.successChannel
-		promise.failureChannel <- nil
 		f(obj)
-	} else {
-		promise.failureChannel <- err
 	}
 }
 
@@ -213,41 +291,98 @@ func executeThen[T any](
 func executeCatch[T any](
 	promise *Promise[T],
 	f func(error),
+	attached string,
 ) {
-	defer promise.recover()
-	defer promise.mutex.Unlock()
+	defer promise.queue.release()
 	defer promise.wg.Done()
-	defer promise.catchExecuted.Store(true)
-	defer promise.clearChannelAfterCatch()
-	err := <-promise.failureChannel
+	defer promise.recoverSubscriber(stepCatch, attached)
+	_, err := promise.result()
 	if err != nil {
-		// there can be another then waiting to execute
-		// putting nil into the failure channel allows
-		// the following then to work correctly
-		promise.failureChannel <- nil
+		promise.handled.Store(true)
 		f(err)
 	}
 }
 
-// drainChannels
-// clears channels
-func (promise *Promise[T]) drainChannels() {
-	if len(promise.failureChannel) == 1 {
-		err := <-promise.failureChannel
-		if err != nil {
-			errMsg := fmt.Sprintf("Promise execution has an unhandled error of %v\nPlease consider using a catch clause to handle errors", err)
-			panic(errMsg)
-		}
+// settle
+// stores the promise's result and hands the
+// queue over to the next promise.
+// Only the first call settles the promise,
+// the following calls return false
+func (promise *Promise[T]) settle(obj T, err error) bool {
+	if !promise.store(obj, err) {
+		return false
+	}
+	promise.queue.release()
+	if promise.cancellation.external {
+		promise.cancellation.finish()
 	}
-	if len(promise.successChannel) == 1 {
-		<-promise.successChannel
+	return true
+}
+
+// store
+// stores the promise's result without handing
+// the queue over, for promises that are settled
+// before their step runs.
+// Only the first call stores the result,
+// the following calls return false
+func (promise *Promise[T]) store(obj T, err error) bool {
+	now := currentClock().Now()
+	promise.resultMutex.Lock()
+	if promise.settled.Load() {
+		promise.resultMutex.Unlock()
+		return false
 	}
+	promise.obj = obj
+	promise.err = err
+	promise.settledAt = now
+	promise.settled.Store(true)
+	promise.resultMutex.Unlock()
+	promise.untrack()
+	promise.traceSettle(err)
+	promise.measureSettle(err, now.Sub(promise.createdAt))
+	promise.logSettle(err)
+	promise.wg.Done()
+	return true
+}
+
+// result
+// returns the promise's value and error
+func (promise *Promise[T]) result() (T, error) {
+	promise.resultMutex.RLock()
+	defer promise.resultMutex.RUnlock()
+	return promise.obj, promise.err
+}
+
+// ensureHandled
+// panics if the promise was rejected and
+// none of its subscribers handled the error,
+// or logs the error if there's a logger.
+// Cancelled promises aren't considered unhandled
+func (promise *Promise[T]) ensureHandled() {
+	_, err := promise.result()
+	var cancelled *CancelledError
+	if err == nil || promise.handled.Load() || errors.As(err, &cancelled) {
+		return
+	}
+	if promise.logUnhandled(err) {
+		return
+	}
+	errMsg := fmt.Sprintf("Promise execution has an unhandled error of %v\nPlease consider using a catch clause to handle errors", err)
+	if promise.origin != nil {
+		errMsg += "\n" + promise.origin.String()
+	}
+	panic(errMsg)
 }
 
 // funcRunner
-// casts call returns to the correct types
-func funcRunner[T any](obj any, args ...meta) (T, error) {
-	obj, err := call(obj, args...)
+// casts call returns to the correct types,
+// a nil interface is the zero value of T
+func funcRunner[T any](function reflect.Value, args ...reflect.Value) (T, error) {
+	obj, err := call(function, args...)
+	if obj == nil {
+		var zero T
+		return zero, err
+	}
 	return obj.(T), err
 }
 
@@ -256,25 +391,35 @@ func funcRunner[T any](obj any, args ...meta) (T, error) {
 // a panic
 func (promise *Promise[T]) recover() {
 	if r := recover(); r != nil {
-		err := fmt.Errorf("Promise entered an unhealth state due to panic:\n %v", r)
-		if len(promise.failureChannel) == 1 {
-			<-promise.failureChannel
-		}
-		promise.failureChannel <- err
+		var obj T
+		err := promise.panicError(r, "")
+		promise.panicked(r, err, "")
+		promise.settle(obj, err)
+	}
+}
+
+// recoverSubscriber
+// recovers if the subscriber of the promise
+// attached at attached panics, the panic
+// replaces the promise's error so the
+// following subscribers can catch it
+func (promise *Promise[T]) recoverSubscriber(subscriber string, attached string) {
+	if r := recover(); r != nil {
+		err := promise.panicError(r, attached)
+		promise.panicked(r, err, subscriber)
+		promise.resultMutex.Lock()
+		defer promise.resultMutex.Unlock()
+		promise.err = err
+		promise.handled.Store(false)
 	}
 }
 
 func Promisify[T any](obj any, args ...any) *Promise[T] {
 	var promise *Promise[T]
 	if isFunction(obj) {
-		argsMeta := make([]meta, 0)
-		for _, arg := range args {
-			argsMeta = append(argsMeta, meta{
-				objectType:  reflect.TypeOf(arg),
-				objectValue: reflect.ValueOf(arg),
-			})
-		}
-		promise = promisifyFunc(funcRunner[T], obj, argsMeta...)
+		function := reflect.ValueOf(obj)
+		plan := planOf(function.Type())
+		promise = promisifyFunc(funcRunner[T], function, plan.values(args)...)
 	} else {
 		promise = promisfyObj(obj.(T))
 	}
@@ -282,36 +427,48 @@ func Promisify[T any](obj any, args ...any) *Promise[T] {
 }
 
 func promisfyObj[T any](obj T) *Promise[T] {
-	promise := newPromise[T]()
+	promise := newPromise[T](context.Background(), stepValue)
 	promise.wg.Add(1)
-	promise.mutex.Lock()
-	go executeObj(promise, obj)
+	promise.queue.enqueue(promise.instrument(stepValue, func() { executeObj(promise, obj) }))
 	return promise
 }
 
 // promisifyFunc
 // Executes the function and creates a promise
 // from the function's result.
-// If the function returns an error, places the err in the failure channel
-// If the function returns an object, puts the object in success channel
-func promisifyFunc[T any](f func(any, ...meta) (T, error), obj any, args ...meta) *Promise[T] {
-	promise := newPromise[T]()
-	promise.wg.Add(1)
-	promise.mutex.Lock()
-	go execute(promise, f, obj, args...)
+// If the function returns an error, the promise is rejected with it
+// If the function returns an object, the promise is fulfilled with it
+func promisifyFunc[T any](f func(reflect.Value, ...reflect.Value) (T, error), function reflect.Value, args ...reflect.Value) *Promise[T] {
+	promise := newPromise[T](context.Background(), stepPromisify)
+	runFunc(promise, f, function, args...)
 	return promise
 }
 
+// instrument
+// wraps the step f of the promise so it runs
+// with its pprof labels and runtime/trace
+// region when they're enabled
+func (promise *Promise[T]) instrument(step string, f func()) func() {
+	return promise.labelled(step, promise.region(step, f))
+}
+
+// runFunc
+// schedules the function's execution
+// on the promise's queue
+func runFunc[T any](promise *Promise[T], f func(reflect.Value, ...reflect.Value) (T, error), function reflect.Value, args ...reflect.Value) {
+	promise.wg.Add(1)
+	promise.queue.enqueue(promise.instrument(promise.step, func() { execute(promise, f, function, args...) }))
+}
+
 // Then
 // runs a function following a promise
 // success and creates a new promise from
 // promise.
 func Then[T, S any](promise *Promise[T], successFunc func(T) (S, error)) *Promise[S] {
-	promise.hasThenSubscriber.Store(true)
-	resultPromise := fromPromise[T, S](promise)
-	promise.mutex.Lock()
-	resultPromise.wg.Add(1)
-	go executeThenCallback(promise, resultPromise, successFunc)
+	promise.Start()
+	promise.subscribed(stepThen)
+	resultPromise := fromPromise[T, S](promise, stepThen)
+	promise.queue.enqueue(resultPromise.instrument(stepThen, func() { executeThenCallback(promise, resultPromise, successFunc) }))
 	return resultPromise
 }
 
@@ -320,41 +477,62 @@ func Then[T, S any](promise *Promise[T], successFunc func(T) (S, error)) *Promis
 // and creates a new promise from the failed
 // promise.
 func Catch[T, S any](promise *Promise[T], catchFunc func(error) (S, error)) *Promise[S] {
-	promise.hasCatchSubscriber.Store(true)
-	resultPromise := fromPromise[T, S](promise)
-	promise.mutex.Lock()
-	resultPromise.wg.Add(1)
-	go executeCatchCallback(promise, resultPromise, catchFunc)
+	promise.Start()
+	promise.subscribed(stepCatch)
+	resultPromise := fromPromise[T, S](promise, stepCatch)
+	promise.queue.enqueue(resultPromise.instrument(stepCatch, func() { executeCatchCallback(promise, resultPromise, catchFunc) }))
+	return resultPromise
+}
+
+// tap
+// creates a promise that settles like promise
+// after f observed its result.
+// f runs even if the new promise is cancelled
+// so it always sees the result
+func tap[T any](promise *Promise[T], f func(T, error)) *Promise[T] {
+	promise.Start()
+	promise.subscribed(stepTap)
+	resultPromise := fromPromise[T, T](promise, stepTap)
+	promise.queue.enqueue(resultPromise.instrument(stepTap, func() { executeTap(promise, resultPromise, f) }))
 	return resultPromise
 }
 
 // Finally
 // runs a function after the promise and subsequent promises
-// were executed.
+// were executed, it blocks until they ran.
 // Ideal for clean up functions
 func (promise *Promise[T]) Finally(finallyFunc func()) {
-	promise.hasFinallySubscriber.Store(true)
-	promise.mutex.Lock()
+	promise.Start()
+	promise.cancellation.pin()
+	promise.subscribed(stepFinally)
 	promise.wg.Add(1)
-	go executeFinally(promise, finallyFunc)
+	promise.queue.enqueueWait(promise.instrument(stepFinally, func() { executeFinally(promise, finallyFunc) }))
 }
 
 // Then
-// executes a function following a promise sucess
+// executes a function following a promise sucess.
+// It blocks until the previous steps of the
+// chain ran
 func (promise *Promise[T]) Then(successFunc func(T)) {
-	promise.hasCatchSubscriber.Store(true)
-	promise.mutex.Lock()
+	promise.Start()
+	promise.cancellation.pin()
+	promise.subscribed(stepThen)
+	attached := attachment()
 	promise.wg.Add(1)
-	go executeThen(promise, successFunc)
+	promise.queue.enqueueWait(promise.instrument(stepThen, func() { executeThen(promise, successFunc, attached) }))
 }
 
 // Catch
-// executes a function following a promise failure
+// executes a function following a promise failure.
+// It blocks until the previous steps of the
+// chain ran
 func (promise *Promise[T]) Catch(errorFunc func(error)) {
-	promise.hasCatchSubscriber.Store(true)
-	promise.mutex.Lock()
+	promise.Start()
+	promise.cancellation.pin()
+	promise.subscribed(stepCatch)
+	attached := attachment()
 	promise.wg.Add(1)
-	go executeCatch(promise, errorFunc)
+	promise.queue.enqueueWait(promise.instrument(stepCatch, func() { executeCatch(promise, errorFunc, attached) }))
 }
 
 // Exec
@@ -364,53 +542,28 @@ func (promise *Promise[T]) Catch(errorFunc func(error)) {
 // It's recommended to use it if neither Finally
 // nor Await are used
 func (promise *Promise[T]) Exec() {
-	promise.mutex.Lock()
-	defer promise.mutex.Unlock()
-	promise.drainChannels()
+	promise.Start()
+	promise.cancellation.pin()
+	promise.subscribed(stepExec)
+	promise.queue.lock()
+	defer promise.queue.release()
+	promise.ensureHandled()
 }
 
 // Await
 // Waits for all of the promises to
 // execute and returns the computed value
-// and the error if there is an error
+// and the error if there is an error.
+// It returns right away once the
+// promise is cancelled
 func (promise *Promise[T]) Await() (T, error) {
-	promise.awaitSubscriber.Store(true)
-	promise.wg.Wait()
-	var obj T
-	var err error
-	if len(promise.successChannel) == 1 {
-		obj = <-promise.successChannel
-	}
-	if len(promise.failureChannel) == 1 {
-		err = <-promise.failureChannel
-	}
-	return obj, err
-}
-
-func (promise *Promise[T]) clearChannelsAfterThen() {
-	hasAwait := promise.awaitSubscriber.Load()
-	hasFinally := promise.hasFinallySubscriber.Load()
-	hasExec := promise.hasExecSubscriber.Load()
-	if hasAwait || hasFinally || hasExec {
-		return
-	}
-	hasCatch := promise.hasCatchSubscriber.Load()
-	catchExecuted := promise.catchExecuted.Load()
-	if !hasCatch || catchExecuted {
-		promise.drainChannels()
-	}
-}
-
-func (promise *Promise[T]) clearChannelAfterCatch() {
-	hasAwait := promise.awaitSubscriber.Load()
-	hasFinally := promise.hasFinallySubscriber.Load()
-	hasExec := promise.hasExecSubscriber.Load()
-	if hasAwait || hasFinally || hasExec {
-		return
-	}
-	hasThen := promise.hasThenSubscriber.Load()
-	thenExecuted := promise.thenExecuted.Load()
-	if !hasThen || thenExecuted {
-		promise.drainChannels()
+	promise.Start()
+	promise.cancellation.pin()
+	promise.subscribed(stepAwait)
+	select {
+	case <-promise.wg.done():
+	case <-promise.cancellation.cancelled():
 	}
+	promise.handled.Store(true)
+	return promise.result()
 }
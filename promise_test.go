@@ -54,6 +54,26 @@ func TestPromiseThenExecution(t *testing.T) {
 		})
 		assert.NoError(t, err)
 	})
+	t.Run("Test then execution creates multiple promises from the same promise", func(t *testing.T) {
+		p := Promisify[testMessage](func(name string, subject string) (testMessage, error) {
+			return testMessage{
+				Name:    name,
+				Subject: subject,
+			}, nil
+		}, "Someone famous", "Hi famous person")
+		p1 := Then(p, func(tm testMessage) (string, error) {
+			return tm.Name, nil
+		})
+		p2 := Then(p, func(tm testMessage) (string, error) {
+			return tm.Subject, nil
+		})
+		name, err := p1.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, name, "Someone famous")
+		subject, err := p2.Await()
+		assert.NoError(t, err)
+		assert.Equal(t, subject, "Hi famous person")
+	})
 }
 
 func TestPromiseWithCatchExecution(t *testing.T) {
@@ -0,0 +1,71 @@
+package promisetest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	promise "github.com/Shehats/go-promisify"
+)
+
+// AssertPending
+// asserts that the promise isn't settled
+func AssertPending[T any](t testing.TB, p *promise.Promise[T]) bool {
+	t.Helper()
+	obj, err, state := p.Peek()
+	if state != promise.Pending {
+		t.Errorf("Promise should be pending but it's %v", describe(obj, err, state))
+		return false
+	}
+	return true
+}
+
+// AssertFulfilled
+// asserts that the promise is fulfilled
+// and returns its value
+func AssertFulfilled[T any](t testing.TB, p *promise.Promise[T]) T {
+	t.Helper()
+	obj, err, state := p.Peek()
+	if state != promise.Fulfilled {
+		t.Errorf("Promise should be fulfilled but it's %v", describe(obj, err, state))
+	}
+	return obj
+}
+
+// AssertFulfilledWith
+// asserts that the promise is fulfilled
+// with a value equal to expected
+func AssertFulfilledWith[T any](t testing.TB, p *promise.Promise[T], expected T) bool {
+	t.Helper()
+	obj, err, state := p.Peek()
+	if state != promise.Fulfilled || !reflect.DeepEqual(obj, expected) {
+		t.Errorf("Promise should be fulfilled with %v but it's %v", expected, describe(obj, err, state))
+		return false
+	}
+	return true
+}
+
+// AssertRejected
+// asserts that the promise is rejected
+// and returns its error
+func AssertRejected[T any](t testing.TB, p *promise.Promise[T]) error {
+	t.Helper()
+	obj, err, state := p.Peek()
+	if state != promise.Rejected {
+		t.Errorf("Promise should be rejected but it's %v", describe(obj, err, state))
+	}
+	return err
+}
+
+// describe
+// describes the state of a promise
+// and what it settled with
+func describe(obj any, err error, state promise.State) string {
+	switch state {
+	case promise.Fulfilled:
+		return fmt.Sprintf("%v with %v", state, obj)
+	case promise.Rejected:
+		return fmt.Sprintf("%v with error: %v", state, err)
+	}
+	return state.String()
+}
@@ -0,0 +1,129 @@
+package promisetest
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	promise "github.com/Shehats/go-promisify"
+)
+
+// Clock is a promise.Clock that only
+// moves when it's advanced
+type Clock struct {
+	mutex sync.Mutex
+	// now is the clock's current time
+	now time.Time
+	// timers waiting for the clock to
+	// reach their time
+	timers []*timer
+	// nextID orders timers with the same time
+	nextID int
+}
+
+// timer is a function waiting for the
+// clock to reach a time
+type timer struct {
+	id int
+	at time.Time
+	f  func()
+}
+
+// NewClock
+// Creates a Clock that starts at now
+func NewClock(now time.Time) *Clock {
+	return &Clock{
+		now: now,
+	}
+}
+
+// UseClock
+// Creates a Clock and makes the promise
+// package use it until the test is done
+func UseClock(t testing.TB) *Clock {
+	clock := NewClock(time.Unix(0, 0))
+	previous := promise.SetClock(clock)
+	t.Cleanup(func() {
+		promise.SetClock(previous)
+	})
+	return clock
+}
+
+// Now
+// returns the clock's current time
+func (clock *Clock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+// AfterFunc
+// calls f once the clock is advanced by d
+func (clock *Clock) AfterFunc(d time.Duration, f func()) func() bool {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	t := &timer{
+		id: clock.nextID,
+		at: clock.now.Add(d),
+		f:  f,
+	}
+	clock.nextID++
+	clock.timers = append(clock.timers, t)
+	return func() bool {
+		return clock.stop(t)
+	}
+}
+
+// stop
+// removes a timer and returns false if
+// it already fired
+func (clock *Clock) stop(t *timer) bool {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	for i, pending := range clock.timers {
+		if pending == t {
+			clock.timers = append(clock.timers[:i], clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Timers
+// returns the number of functions waiting
+// for the clock to advance
+func (clock *Clock) Timers() int {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return len(clock.timers)
+}
+
+// Advance
+// moves the clock forward by d and calls the
+// functions that were waiting for it, in the
+// order of their time, in the caller's go routine
+func (clock *Clock) Advance(d time.Duration) {
+	clock.mutex.Lock()
+	clock.now = clock.now.Add(d)
+	due := make([]*timer, 0)
+	pending := make([]*timer, 0, len(clock.timers))
+	for _, t := range clock.timers {
+		if t.at.After(clock.now) {
+			pending = append(pending, t)
+		} else {
+			due = append(due, t)
+		}
+	}
+	clock.timers = pending
+	clock.mutex.Unlock()
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].at.Equal(due[j].at) {
+			return due[i].id < due[j].id
+		}
+		return due[i].at.Before(due[j].at)
+	})
+	for _, t := range due {
+		t.f()
+	}
+}
@@ -0,0 +1,47 @@
+package promisetest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	promise "github.com/Shehats/go-promisify"
+)
+
+// LeakTimeout is how long VerifyNoLeaks waits
+// for the promises created by a test to settle
+// before reporting them as leaked
+var LeakTimeout = 500 * time.Millisecond
+
+// VerifyNoLeaks
+// fails the test if any of the promises
+// created during the test is still pending
+// when the test is done
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+	previous := promise.SetLeakTracking(true)
+	existing := map[uint64]bool{}
+	for _, leak := range promise.LeakedPromises() {
+		existing[leak.ID] = true
+	}
+	t.Cleanup(func() {
+		defer promise.SetLeakTracking(previous)
+		deadline := time.Now().Add(LeakTimeout)
+		for {
+			leaks := make([]string, 0)
+			for _, leak := range promise.LeakedPromises() {
+				if !existing[leak.ID] {
+					leaks = append(leaks, leak.String())
+				}
+			}
+			if len(leaks) == 0 {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("%d promises created by the test are still pending:\n%s", len(leaks), strings.Join(leaks, "\n"))
+				return
+			}
+			time.Sleep(LeakTimeout / 50)
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package promisetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	promise "github.com/Shehats/go-promisify"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingT records the failures of
+// the assertions under test
+type recordingT struct {
+	testing.TB
+	failures []string
+	cleanups []func()
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *recordingT) cleanup() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("Runs the steps only when stepped", func(t *testing.T) {
+		scheduler := UseScheduler(t)
+		steps := make([]string, 0)
+		p := promise.Promisify[string](func() (string, error) {
+			steps = append(steps, "promisify")
+			return "Someone famous", nil
+		})
+		p1 := promise.Then(p, func(name string) (int, error) {
+			steps = append(steps, "then")
+			return len(name), nil
+		})
+		promise.Then(p, func(name string) (string, error) {
+			steps = append(steps, "subscriber")
+			return name, nil
+		})
+		AssertPending(t, p)
+		assert.Equal(t, scheduler.Pending(), 1)
+		assert.True(t, scheduler.Step())
+		AssertFulfilledWith(t, p, "Someone famous")
+		AssertPending(t, p1)
+		assert.Equal(t, scheduler.RunUntilIdle(), 2)
+		AssertFulfilledWith(t, p1, len("Someone famous"))
+		assert.Equal(t, steps, []string{"promisify", "then", "subscriber"})
+		assert.False(t, scheduler.Step())
+	})
+	t.Run("Runs the steps of a rejected chain", func(t *testing.T) {
+		scheduler := UseScheduler(t)
+		p := promise.Promisify[string](func() (string, error) {
+			return "", errors.New("Famous people don't shake hands")
+		})
+		p1 := promise.Catch(p, func(err error) (string, error) {
+			return "Stunt Double", nil
+		})
+		scheduler.RunUntilIdle()
+		assert.EqualError(t, AssertRejected(t, p), "Famous people don't shake hands")
+		assert.Equal(t, AssertFulfilled(t, p1), "Stunt Double")
+	})
+	t.Run("Runs the steps with the highest priority first", func(t *testing.T) {
+		scheduler := UseScheduler(t)
+		steps := make([]string, 0)
+		step := func(ctx context.Context, name string) (string, error) {
+			steps = append(steps, name)
+			return name, nil
+		}
+		reindex := promise.PromisifyContext[string](promise.WithPriority(context.Background(), promise.Low), step, "reindex")
+		report := promise.PromisifyContext[string](context.Background(), step, "report")
+		request := promise.PromisifyContext[string](promise.WithPriority(context.Background(), promise.High), step, "request")
+		scheduler.RunUntilIdle()
+		assert.Equal(t, steps, []string{"request", "report", "reindex"})
+		for _, p := range []*promise.Promise[string]{reindex, report, request} {
+			AssertFulfilled(t, p)
+		}
+	})
+}
+
+func TestClock(t *testing.T) {
+	t.Run("Settles delays when advanced", func(t *testing.T) {
+		scheduler := UseScheduler(t)
+		clock := UseClock(t)
+		first := promise.Delay(time.Second, "first")
+		second := promise.Delay(2*time.Second, "second")
+		scheduler.RunUntilIdle()
+		assert.Equal(t, clock.Timers(), 2)
+		clock.Advance(time.Second)
+		scheduler.RunUntilIdle()
+		AssertFulfilledWith(t, first, "first")
+		AssertPending(t, second)
+		clock.Advance(time.Second)
+		scheduler.RunUntilIdle()
+		AssertFulfilledWith(t, second, "second")
+		assert.Equal(t, clock.Now(), time.Unix(2, 0))
+	})
+	t.Run("Calls the functions in the order of their time", func(t *testing.T) {
+		clock := NewClock(time.Unix(0, 0))
+		calls := make([]int, 0)
+		clock.AfterFunc(2*time.Second, func() { calls = append(calls, 2) })
+		clock.AfterFunc(time.Second, func() { calls = append(calls, 1) })
+		stop := clock.AfterFunc(time.Second, func() { calls = append(calls, 3) })
+		assert.True(t, stop())
+		clock.Advance(time.Minute)
+		assert.Equal(t, calls, []int{1, 2})
+		assert.False(t, stop())
+	})
+}
+
+func TestAssertions(t *testing.T) {
+	scheduler := UseScheduler(t)
+	fulfilled := promise.Promisify[string]("Someone famous")
+	rejected := promise.Promisify[string](func() (string, error) {
+		return "", errors.New("Famous people don't shake hands")
+	})
+	pending := promise.NewDeferred[string]().Promise()
+	scheduler.RunUntilIdle()
+
+	t.Run("Passes when the state matches", func(t *testing.T) {
+		recorder := &recordingT{}
+		AssertPending(recorder, pending)
+		AssertFulfilled(recorder, fulfilled)
+		AssertFulfilledWith(recorder, fulfilled, "Someone famous")
+		AssertRejected(recorder, rejected)
+		assert.Empty(t, recorder.failures)
+	})
+	t.Run("Fails when the state doesn't match", func(t *testing.T) {
+		recorder := &recordingT{}
+		AssertPending(recorder, fulfilled)
+		AssertFulfilled(recorder, rejected)
+		AssertFulfilledWith(recorder, fulfilled, "Someone else")
+		AssertRejected(recorder, pending)
+		assert.Equal(t, recorder.failures, []string{
+			"Promise should be pending but it's fulfilled with Someone famous",
+			"Promise should be fulfilled but it's rejected with error: Famous people don't shake hands",
+			"Promise should be fulfilled with Someone else but it's fulfilled with Someone famous",
+			"Promise should be rejected but it's pending",
+		})
+	})
+}
+
+func TestVerifyNoLeaks(t *testing.T) {
+	t.Run("Passes when the promises settle", func(t *testing.T) {
+		recorder := &recordingT{}
+		VerifyNoLeaks(recorder)
+		d := promise.NewDeferred[string]()
+		p := promise.Promisify[string](func() (string, error) {
+			return "Someone famous", nil
+		})
+		go d.Resolve("Someone famous")
+		recorder.cleanup()
+		assert.Empty(t, recorder.failures)
+		p.Await()
+	})
+	t.Run("Fails when a promise is pending", func(t *testing.T) {
+		timeout := LeakTimeout
+		LeakTimeout = 50 * time.Millisecond
+		defer func() {
+			LeakTimeout = timeout
+		}()
+		recorder := &recordingT{}
+		VerifyNoLeaks(recorder)
+		d := promise.NewDeferred[string]()
+		recorder.cleanup()
+		d.Resolve("Someone famous")
+		assert.Len(t, recorder.failures, 1)
+		assert.Contains(t, recorder.failures[0], "1 promises created by the test are still pending")
+		assert.Contains(t, recorder.failures[0], "TestVerifyNoLeaks")
+	})
+}
@@ -0,0 +1,114 @@
+// Package promisetest provides a deterministic
+// scheduler, a fake clock and assertions to test
+// code that uses promises reproducibly.
+//
+// The scheduler and the clock replace the ones
+// used by the promise package, so tests that use
+// them can't run in parallel
+package promisetest
+
+import (
+	"sync"
+	"testing"
+
+	promise "github.com/Shehats/go-promisify"
+)
+
+// Scheduler is a promise.Prioritizer that
+// doesn't run anything by itself, the
+// steps of the promises are queued and
+// run one at a time in the caller's go
+// routine by Step and RunUntilIdle.
+// Await and Exec block until the steps
+// they wait for run, so the scheduler has
+// to be run before calling them
+type Scheduler struct {
+	mutex sync.Mutex
+	// tasks waiting to be run
+	tasks []scheduledTask
+}
+
+// scheduledTask is a task
+// waiting to be run
+type scheduledTask struct {
+	run      func()
+	priority promise.Priority
+}
+
+// NewScheduler
+// Creates a new Scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// UseScheduler
+// Creates a Scheduler and makes the promises
+// created by the test use it until the test
+// is done
+func UseScheduler(t testing.TB) *Scheduler {
+	scheduler := NewScheduler()
+	previous := promise.SetExecutor(scheduler)
+	t.Cleanup(func() {
+		promise.SetExecutor(previous)
+	})
+	return scheduler
+}
+
+// Execute
+// queues the task with Normal priority
+// until it's run by Step or RunUntilIdle
+func (scheduler *Scheduler) Execute(task func()) {
+	scheduler.ExecutePriority(task, promise.Normal)
+}
+
+// ExecutePriority
+// queues the task with priority until
+// it's run by Step or RunUntilIdle
+func (scheduler *Scheduler) ExecutePriority(task func(), priority promise.Priority) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	scheduler.tasks = append(scheduler.tasks, scheduledTask{run: task, priority: priority})
+}
+
+// Pending
+// returns the number of tasks waiting to run
+func (scheduler *Scheduler) Pending() int {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	return len(scheduler.tasks)
+}
+
+// Step
+// runs the next task with the highest priority
+// in the order they were queued and returns
+// false if there wasn't any
+func (scheduler *Scheduler) Step() bool {
+	scheduler.mutex.Lock()
+	if len(scheduler.tasks) == 0 {
+		scheduler.mutex.Unlock()
+		return false
+	}
+	next := 0
+	for i, task := range scheduler.tasks {
+		if task.priority > scheduler.tasks[next].priority {
+			next = i
+		}
+	}
+	task := scheduler.tasks[next]
+	scheduler.tasks = append(scheduler.tasks[:next], scheduler.tasks[next+1:]...)
+	scheduler.mutex.Unlock()
+	task.run()
+	return true
+}
+
+// RunUntilIdle
+// runs tasks, including the ones queued by the
+// tasks that run, until there are none left and
+// returns how many tasks ran
+func (scheduler *Scheduler) RunUntilIdle() int {
+	steps := 0
+	for scheduler.Step() {
+		steps++
+	}
+	return steps
+}
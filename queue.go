@@ -0,0 +1,163 @@
+package promise
+
+import "sync"
+
+// queue runs the steps of a promise chain
+// one after the other in the order they
+// were attached, without blocking the
+// caller that attaches them.
+// A step owns the queue until it calls
+// release, which starts the next step
+type queue struct {
+	mutex sync.Mutex
+	// executor that runs the steps
+	executor Executor
+	// priority of the steps if the
+	// executor is a Prioritizer
+	priority Priority
+	// busy is true while a step owns the queue
+	busy bool
+	// steps that are waiting for the queue
+	steps []func()
+	// task is the runtime/trace task of
+	// the chain, nil if it isn't traced
+	task *chainTask
+}
+
+// newQueue
+// Creates a queue that runs its steps with
+// priority using the package's executor
+func newQueue(priority Priority) *queue {
+	return &queue{
+		executor: currentExecutor(),
+		priority: priority,
+	}
+}
+
+// enqueue
+// executes the step once
+// all of the previous steps released
+// the queue
+func (q *queue) enqueue(step func()) {
+	q.mutex.Lock()
+	if q.busy {
+		q.steps = append(q.steps, step)
+		q.mutex.Unlock()
+		return
+	}
+	q.busy = true
+	q.task.begin()
+	q.mutex.Unlock()
+	q.execute(step)
+}
+
+// enqueueWait
+// blocks the caller until all of the previous
+// steps released the queue and then executes
+// the step without waiting for it
+func (q *queue) enqueueWait(step func()) {
+	q.lock()
+	q.execute(step)
+}
+
+// release
+// hands the queue over to the next step
+func (q *queue) release() {
+	q.mutex.Lock()
+	if len(q.steps) == 0 {
+		q.busy = false
+		q.task.end()
+		q.mutex.Unlock()
+		return
+	}
+	step := q.steps[0]
+	// the step's promise can be
+	// collected once it ran
+	q.steps[0] = nil
+	q.steps = q.steps[1:]
+	q.mutex.Unlock()
+	q.execute(step)
+}
+
+// execute
+// runs the step with the queue's priority
+// if the executor is a Prioritizer
+func (q *queue) execute(step func()) {
+	if prioritizer, ok := q.executor.(Prioritizer); ok {
+		prioritizer.ExecutePriority(step, q.priority)
+		return
+	}
+	q.executor.Execute(step)
+}
+
+// hold
+// keeps the queue busy until release is
+// called by whoever settles the promise
+func (q *queue) hold() {
+	q.enqueue(func() {})
+}
+
+// lock
+// blocks the caller until it owns the queue,
+// the caller has to release it when it's done
+func (q *queue) lock() {
+	owned := make(chan struct{})
+	q.enqueue(func() { close(owned) })
+	<-owned
+}
+
+// waitGroup counts the steps of a chain that
+// didn't settle their promise yet like a
+// sync.WaitGroup, and can also be waited
+// for in a select
+type waitGroup struct {
+	mutex sync.Mutex
+	count int
+	// idle is closed once
+	// count drops to zero
+	idle chan struct{}
+}
+
+// Add
+// adds delta to the count
+func (wg *waitGroup) Add(delta int) {
+	wg.mutex.Lock()
+	defer wg.mutex.Unlock()
+	if wg.count == 0 {
+		wg.idle = nil
+	}
+	wg.count += delta
+	if wg.count < 0 {
+		panic("Promise wait group has a negative count")
+	}
+	if wg.count == 0 && wg.idle != nil {
+		close(wg.idle)
+	}
+}
+
+// Done
+// decrements the count
+func (wg *waitGroup) Done() {
+	wg.Add(-1)
+}
+
+// Wait
+// blocks until the count is zero
+func (wg *waitGroup) Wait() {
+	<-wg.done()
+}
+
+// done
+// returns a channel that is closed
+// once the count is zero
+func (wg *waitGroup) done() <-chan struct{} {
+	wg.mutex.Lock()
+	defer wg.mutex.Unlock()
+	if wg.idle == nil {
+		wg.idle = make(chan struct{})
+		if wg.count == 0 {
+			close(wg.idle)
+		}
+	}
+	return wg.idle
+}
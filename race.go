@@ -0,0 +1,211 @@
+package promise
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// AggregateError is the error a promise
+// is rejected with when all of the promises
+// it depends on were rejected
+type AggregateError struct {
+	// Errors of the promises in the
+	// order of the promises
+	Errors []error
+}
+
+// Error
+// returns the error message listing
+// every error
+func (err *AggregateError) Error() string {
+	msg := strings.Builder{}
+	fmt.Fprintf(&msg, "All %d promises were rejected:", len(err.Errors))
+	for i, e := range err.Errors {
+		fmt.Fprintf(&msg, "\n %d. %v", i+1, e)
+	}
+	return msg.String()
+}
+
+// Unwrap
+// returns the errors so errors.Is and
+// errors.As look into each of them
+func (err *AggregateError) Unwrap() []error {
+	return err.Errors
+}
+
+// racer settles a promise with the first of
+// the promises that enter the race to settle,
+// or to be fulfilled
+type racer[T any] struct {
+	deferred *Deferred[T]
+	mutex    sync.Mutex
+	// entrants of the race
+	entrants []*Promise[T]
+	// pending is the number of
+	// entrants that aren't settled
+	pending int
+	// errs of the rejected entrants
+	// in the order they entered
+	errs []error
+	// closed is true once no more
+	// promises enter the race
+	closed bool
+	// fulfilled is true if only a fulfilled
+	// entrant wins the race
+	fulfilled bool
+	// owned is true if the racer created the
+	// entrants, so it cancels the losers and
+	// cancelling the race cancels them
+	owned bool
+	// done is called once the race is decided
+	done []func()
+	// over is true once the race is decided
+	over bool
+	// reason the losers are cancelled with
+	reason *CancelledError
+}
+
+// newRacer
+// Creates a racer with
+// no entrants yet
+func newRacer[T any](fulfilled bool, owned bool) *racer[T] {
+	r := &racer[T]{
+		deferred:  NewDeferred[T](),
+		fulfilled: fulfilled,
+		owned:     owned,
+	}
+	if owned {
+		r.deferred.promise.OnCancel(func() {
+			r.decided(r.deferred.promise.cancelledWith())
+		})
+	}
+	return r
+}
+
+// enter
+// adds promise to the race
+func (r *racer[T]) enter(promise *Promise[T]) {
+	r.mutex.Lock()
+	i := len(r.entrants)
+	r.entrants = append(r.entrants, promise)
+	r.errs = append(r.errs, nil)
+	r.pending++
+	over, reason := r.over, r.reason
+	r.mutex.Unlock()
+	tap(promise, func(obj T, err error) {
+		r.settled(i, obj, err)
+	})
+	// a late entrant of a decided
+	// race lost it already
+	if over && r.owned && reason != nil {
+		promise.cancel(reason)
+	}
+}
+
+// onDone
+// calls f once the race is decided,
+// right away if it already is
+func (r *racer[T]) onDone(f func()) {
+	r.mutex.Lock()
+	if !r.over {
+		r.done = append(r.done, f)
+		r.mutex.Unlock()
+		return
+	}
+	r.mutex.Unlock()
+	f()
+}
+
+// close
+// stops promises from entering the race
+// and rejects it if every entrant was
+func (r *racer[T]) close() {
+	r.mutex.Lock()
+	r.closed = true
+	r.mutex.Unlock()
+	r.rejectIfLost()
+}
+
+// settled
+// decides the race if the entrant wins it
+func (r *racer[T]) settled(i int, obj T, err error) {
+	if err == nil || !r.fulfilled {
+		if r.deferred.promise.settle(obj, err) {
+			r.decided(&CancelledError{Reason: fmt.Errorf("Promise lost the race")})
+		}
+		return
+	}
+	r.mutex.Lock()
+	r.pending--
+	r.errs[i] = err
+	r.mutex.Unlock()
+	r.rejectIfLost()
+}
+
+// rejectIfLost
+// rejects the race with the errors of the
+// entrants once they all were rejected
+func (r *racer[T]) rejectIfLost() {
+	r.mutex.Lock()
+	lost := r.closed && r.pending == 0
+	errs := r.errs
+	r.mutex.Unlock()
+	if lost && r.deferred.Reject(&AggregateError{Errors: errs}) {
+		r.decided(nil)
+	}
+}
+
+// decided
+// cancels the losers of an owned race with
+// reason and calls the done functions
+func (r *racer[T]) decided(reason *CancelledError) {
+	r.mutex.Lock()
+	r.over = true
+	r.reason = reason
+	entrants := r.entrants
+	done := r.done
+	r.done = nil
+	r.mutex.Unlock()
+	for _, f := range done {
+		f()
+	}
+	if r.owned && reason != nil {
+		for _, entrant := range entrants {
+			entrant.cancel(reason)
+		}
+	}
+}
+
+// Race
+// Creates a promise that settles like the
+// first of promises to settle, fulfilled
+// or rejected
+func Race[T any](promises ...*Promise[T]) *Promise[T] {
+	if len(promises) == 0 {
+		panic("Promise can't race without promises")
+	}
+	r := newRacer[T](false, false)
+	for _, promise := range promises {
+		r.enter(promise)
+	}
+	r.close()
+	return r.deferred.promise
+}
+
+// Any
+// Creates a promise that is fulfilled like
+// the first of promises to be fulfilled, or
+// rejected with an *AggregateError if all
+// of them are rejected
+func Any[T any](promises ...*Promise[T]) *Promise[T] {
+	if len(promises) == 0 {
+		panic("Promise can't race without promises")
+	}
+	r := newRacer[T](true, false)
+	for _, promise := range promises {
+		r.enter(promise)
+	}
+	r.close()
+	return r.deferred.promise
+}
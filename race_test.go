@@ -0,0 +1,64 @@
+package promise
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRace(t *testing.T) {
+	t.Run("Settles like the first promise to settle", func(t *testing.T) {
+		first := NewDeferred[string]()
+		second := NewDeferred[string]()
+		p := Race(first.Promise(), second.Promise())
+		second.Reject(errors.New("Famous people don't shake hands"))
+		_, err := p.Await()
+		assert.EqualError(t, err, "Famous people don't shake hands")
+
+		first.Resolve("Someone famous")
+		name, err := first.Promise().Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Fulfills with the first promise to be fulfilled", func(t *testing.T) {
+		p := Race(NewDeferred[string]().Promise(), Promisify[string]("Someone famous"))
+		name, err := p.Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Panics without promises", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Race[string]()
+		})
+	})
+}
+
+func TestAny(t *testing.T) {
+	t.Run("Fulfills with the first promise to be fulfilled", func(t *testing.T) {
+		first := NewDeferred[string]()
+		second := NewDeferred[string]()
+		p := Any(first.Promise(), second.Promise())
+		second.Reject(errors.New("Famous people don't shake hands"))
+		assert.Equal(t, p.State(), Pending)
+		first.Resolve("Someone famous")
+		name, err := p.Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Someone famous")
+	})
+	t.Run("Rejects with every error when all promises are rejected", func(t *testing.T) {
+		errNoHands := errors.New("Famous people don't shake hands")
+		errNoAutographs := errors.New("Famous people don't sign autographs")
+		first := NewDeferred[string]()
+		second := NewDeferred[string]()
+		p := Any(first.Promise(), second.Promise())
+		second.Reject(errNoAutographs)
+		first.Reject(errNoHands)
+		_, err := p.Await()
+		var aggregate *AggregateError
+		assert.ErrorAs(t, err, &aggregate)
+		assert.Equal(t, aggregate.Errors, []error{errNoHands, errNoAutographs})
+		assert.ErrorIs(t, err, errNoHands)
+		assert.EqualError(t, err, "All 2 promises were rejected:\n 1. Famous people don't shake hands\n 2. Famous people don't sign autographs")
+	})
+}
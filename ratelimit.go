@@ -0,0 +1,164 @@
+package promise
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits how often promises
+// are created with a token bucket: it holds
+// up to burst tokens, refills rate tokens per
+// second and every call takes a token.
+// It's safe to use from any go routine so one
+// limiter can be shared by every call to a
+// rate limited API
+type RateLimiter struct {
+	mutex sync.Mutex
+	// rate is the number of tokens
+	// added per second
+	rate float64
+	// burst is the size of the bucket
+	burst float64
+	// tokens in the bucket, negative
+	// when calls are waiting for tokens
+	tokens float64
+	// last is when tokens was updated
+	last time.Time
+}
+
+// NewRateLimiter
+// Creates a RateLimiter that allows rate calls
+// per second on average and up to burst calls
+// at once, the bucket starts full
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 || burst <= 0 {
+		panic("RateLimiter rate and burst have to be positive")
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   currentClock().Now(),
+	}
+}
+
+// Limit
+// calls factory once the limiter has a token
+// and returns a promise that settles like the
+// promise it creates, see LimitContext
+func Limit[T any](limiter *RateLimiter, factory func() *Promise[T]) *Promise[T] {
+	return LimitContext(context.Background(), limiter, factory)
+}
+
+// LimitContext
+// calls factory once the limiter has a token
+// and returns a promise that settles like the
+// promise it creates. The returned promise is
+// cancelled if ctx is done while it waits for
+// a token. Cancelling the returned promise
+// stops the wait or cancels the promise
+// factory created
+func LimitContext[T any](ctx context.Context, limiter *RateLimiter, factory func() *Promise[T]) *Promise[T] {
+	deferred := NewDeferred[T]()
+	wait := limiter.WaitContext(ctx)
+	deferred.promise.OnCancel(func() {
+		wait.cancel(deferred.promise.cancelledWith())
+	})
+	tap(wait, func(_ struct{}, err error) {
+		if err != nil {
+			deferred.Reject(err)
+			return
+		}
+		deferred.follow(factory, func() {})
+	})
+	return deferred.promise
+}
+
+// Wait
+// returns a promise that is fulfilled
+// once the limiter has a token for the
+// caller, see WaitContext
+func (limiter *RateLimiter) Wait() *Promise[struct{}] {
+	return limiter.WaitContext(context.Background())
+}
+
+// WaitContext
+// returns a promise that is fulfilled once
+// the limiter has a token for the caller.
+// The promise is cancelled if ctx is done
+// before, and cancelling it gives the
+// token back
+func (limiter *RateLimiter) WaitContext(ctx context.Context) *Promise[struct{}] {
+	deferred := NewDeferred[struct{}]()
+	if ctx.Err() != nil {
+		deferred.promise.Cancel(context.Cause(ctx))
+		return deferred.promise
+	}
+	d := limiter.reserve()
+	if d == 0 {
+		deferred.Resolve(struct{}{})
+		return deferred.promise
+	}
+	stopWatch := context.AfterFunc(ctx, func() {
+		deferred.promise.Cancel(context.Cause(ctx))
+	})
+	stopTimer := currentClock().AfterFunc(d, func() {
+		stopWatch()
+		deferred.Resolve(struct{}{})
+	})
+	deferred.promise.OnCancel(func() {
+		stopWatch()
+		stopTimer()
+		limiter.restore()
+	})
+	return deferred.promise
+}
+
+// Tokens
+// returns the number of tokens in the
+// bucket, negative when calls are
+// waiting for tokens
+func (limiter *RateLimiter) Tokens() float64 {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	limiter.refill()
+	return limiter.tokens
+}
+
+// refill
+// adds the tokens that accumulated
+// since the last update
+func (limiter *RateLimiter) refill() {
+	now := currentClock().Now()
+	elapsed := now.Sub(limiter.last)
+	limiter.last = now
+	if elapsed > 0 {
+		limiter.tokens = math.Min(limiter.burst, limiter.tokens+elapsed.Seconds()*limiter.rate)
+	}
+}
+
+// reserve
+// takes a token and returns how long
+// the caller has to wait for it
+func (limiter *RateLimiter) reserve() time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	limiter.refill()
+	limiter.tokens--
+	if limiter.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(-limiter.tokens / limiter.rate * float64(time.Second)))
+}
+
+// restore
+// gives back the token of
+// a cancelled wait
+func (limiter *RateLimiter) restore() {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	limiter.refill()
+	limiter.tokens = math.Min(limiter.burst, limiter.tokens+1)
+}
@@ -0,0 +1,79 @@
+package promise
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("Lets the burst through and then waits for tokens", func(t *testing.T) {
+		clock := useTestClock(t)
+		limiter := NewRateLimiter(2, 2)
+		calls := 0
+		factory := func() *Promise[int] {
+			calls++
+			return Promisify[int](calls)
+		}
+		first, _ := Limit(limiter, factory).Await()
+		second, _ := Limit(limiter, factory).Await()
+		assert.Equal(t, []int{first, second}, []int{1, 2})
+
+		third := Limit(limiter, factory)
+		fourth := Limit(limiter, factory)
+		assert.Equal(t, limiter.Tokens(), -2.0)
+		clock.Advance(499 * time.Millisecond)
+		assert.Equal(t, third.State(), Pending)
+		clock.Advance(time.Millisecond)
+		n, _ := third.Await()
+		assert.Equal(t, n, 3)
+		assert.Equal(t, fourth.State(), Pending)
+		clock.Advance(500 * time.Millisecond)
+		n, _ = fourth.Await()
+		assert.Equal(t, n, 4)
+	})
+	t.Run("Refills up to the burst", func(t *testing.T) {
+		clock := useTestClock(t)
+		limiter := NewRateLimiter(1, 3)
+		limiter.Wait().Await()
+		clock.Advance(time.Hour)
+		assert.Equal(t, limiter.Tokens(), 3.0)
+	})
+	t.Run("Gives the token back when the wait is cancelled", func(t *testing.T) {
+		clock := useTestClock(t)
+		limiter := NewRateLimiter(1, 1)
+		limiter.Wait().Await()
+		ctx, cancel := context.WithCancel(context.Background())
+		p := LimitContext(ctx, limiter, func() *Promise[string] {
+			assert.Fail(t, "This should never get called")
+			return nil
+		})
+		assert.Equal(t, limiter.Tokens(), -1.0)
+		cancel()
+		_, err := p.Await()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, limiter.Tokens(), 0.0)
+		assert.Equal(t, clock.timers, []*testTimer{})
+
+		p = Limit(limiter, func() *Promise[string] {
+			assert.Fail(t, "This should never get called")
+			return nil
+		})
+		assert.True(t, p.Cancel(nil))
+		_, err = p.Await()
+		var cancelled *CancelledError
+		assert.ErrorAs(t, err, &cancelled)
+		assert.Equal(t, limiter.Tokens(), 0.0)
+	})
+	t.Run("Doesn't wait when the context is already done", func(t *testing.T) {
+		useTestClock(t)
+		limiter := NewRateLimiter(1, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := limiter.WaitContext(ctx).Await()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, limiter.Tokens(), 1.0)
+	})
+}
@@ -0,0 +1,99 @@
+package promise
+
+import (
+	"context"
+	"runtime/trace"
+	"sync/atomic"
+)
+
+// runtimeTrace is true while the promises
+// are traced with runtime/trace
+var runtimeTrace atomic.Bool
+
+// SetRuntimeTrace
+// makes each chain of promises created from now
+// on while runtime/trace is tracing a trace.Task,
+// and each of its steps a trace.Region named after
+// the step, and returns whether they were before.
+// Ideal for following a chain's execution
+// with go tool trace
+func SetRuntimeTrace(enabled bool) bool {
+	return runtimeTrace.Swap(enabled)
+}
+
+// chainTask is the trace.Task of a chain,
+// it runs while the chain has steps to run
+// and begins again if steps are attached
+// after it ended
+type chainTask struct {
+	name string
+	// parent is the context
+	// the task is created from
+	parent context.Context
+	// ctx of the running task
+	ctx  context.Context
+	task *trace.Task
+}
+
+// begin
+// creates the task unless it's running,
+// the caller holds the queue's mutex
+func (task *chainTask) begin() {
+	if task == nil || task.task != nil {
+		return
+	}
+	task.ctx, task.task = trace.NewTask(task.parent, task.name)
+}
+
+// end
+// ends the task if it's running,
+// the caller holds the queue's mutex
+func (task *chainTask) end() {
+	if task == nil || task.task == nil {
+		return
+	}
+	task.task.End()
+	task.task = nil
+}
+
+// startTask
+// creates the trace.Task of the chain of a
+// promise that isn't created from another one.
+// The task ends every time the chain runs out
+// of steps, a lazy chain's task begins once
+// it's started
+func (promise *Promise[T]) startTask() {
+	if !runtimeTrace.Load() || !trace.IsEnabled() {
+		return
+	}
+	task := &chainTask{name: "promise." + promise.step, parent: promise.ctx}
+	promise.queue.task = task
+	if promise.step != stepLazy {
+		task.begin()
+		promise.ctx = task.ctx
+	}
+}
+
+// region
+// wraps the step f of the promise so it runs
+// in a trace.Region while it's traced
+func (promise *Promise[T]) region(step string, f func()) func() {
+	if !runtimeTrace.Load() || !trace.IsEnabled() {
+		return f
+	}
+	return func() {
+		trace.WithRegion(promise.queue.taskContext(promise.ctx), "promise."+step, f)
+	}
+}
+
+// taskContext
+// returns the context of the chain's running
+// task, ctx if the chain isn't traced
+func (q *queue) taskContext(ctx context.Context) context.Context {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.task == nil || q.task.task == nil {
+		return ctx
+	}
+	return q.task.ctx
+}
@@ -0,0 +1,108 @@
+package promise
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runtimeTraceOf
+// returns the runtime/trace of f
+func runtimeTraceOf(t *testing.T, f func()) []byte {
+	var buffer bytes.Buffer
+	if err := trace.Start(&buffer); err != nil {
+		t.Skipf("Can't start tracing: %v", err)
+	}
+	f()
+	trace.Stop()
+	return buffer.Bytes()
+}
+
+// taskRunning
+// returns true if the task
+// of the chain is running
+func taskRunning(q *queue) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.task != nil && q.task.task != nil
+}
+
+func TestRuntimeTrace(t *testing.T) {
+	t.Run("Traces the chain as a task with a region per step", func(t *testing.T) {
+		previous := SetRuntimeTrace(true)
+		t.Cleanup(func() {
+			SetRuntimeTrace(previous)
+		})
+		trace := runtimeTraceOf(t, func() {
+			p := Promisify[testMessage](func() (testMessage, error) {
+				return testMessage{Name: "Someone famous"}, nil
+			})
+			p1 := Then(p, func(tm testMessage) (string, error) {
+				return tm.Name, nil
+			})
+			p1.Finally(func() {})
+			p1.Await()
+		})
+		assert.Contains(t, string(trace), "promise.then")
+		assert.Contains(t, string(trace), "promise.finally")
+	})
+	t.Run("Traces the steps attached after the chain ran out of steps", func(t *testing.T) {
+		previous := SetRuntimeTrace(true)
+		t.Cleanup(func() {
+			SetRuntimeTrace(previous)
+		})
+		runtimeTraceOf(t, func() {
+			p := Promisify[string]("Someone famous")
+			p.Await()
+			assert.Eventually(t, func() bool {
+				return !taskRunning(p.queue)
+			}, time.Second, time.Millisecond)
+			running := false
+			Then(p, func(name string) (string, error) {
+				running = taskRunning(p.queue)
+				return name, nil
+			}).Await()
+			assert.True(t, running)
+			assert.Eventually(t, func() bool {
+				return !taskRunning(p.queue)
+			}, time.Second, time.Millisecond)
+		})
+	})
+	t.Run("Doesn't begin the task of a lazy chain until it's started", func(t *testing.T) {
+		previous := SetRuntimeTrace(true)
+		t.Cleanup(func() {
+			SetRuntimeTrace(previous)
+		})
+		runtimeTraceOf(t, func() {
+			p := Lazy[string](func() (string, error) {
+				return "Someone famous", nil
+			})
+			assert.False(t, taskRunning(p.queue))
+			running := false
+			Then(p, func(name string) (string, error) {
+				running = taskRunning(p.queue)
+				return name, nil
+			}).Await()
+			assert.True(t, running)
+			assert.Eventually(t, func() bool {
+				return !taskRunning(p.queue)
+			}, time.Second, time.Millisecond)
+		})
+	})
+	t.Run("Doesn't trace the chain when disabled", func(t *testing.T) {
+		previous := SetRuntimeTrace(false)
+		t.Cleanup(func() {
+			SetRuntimeTrace(previous)
+		})
+		trace := runtimeTraceOf(t, func() {
+			p := Promisify[string]("Someone famous")
+			Then(p, func(name string) (string, error) {
+				return name, nil
+			}).Await()
+		})
+		assert.NotContains(t, string(trace), "promise.then")
+	})
+}
@@ -0,0 +1,55 @@
+package promise
+
+// State of a promise
+type State int
+
+const (
+	// Pending is the state of a promise
+	// that isn't settled yet
+	Pending State = iota
+	// Fulfilled is the state of a promise
+	// that has a value
+	Fulfilled
+	// Rejected is the state of a promise
+	// that has an error
+	Rejected
+)
+
+// String
+// returns the state's name
+func (state State) String() string {
+	switch state {
+	case Pending:
+		return "pending"
+	case Fulfilled:
+		return "fulfilled"
+	case Rejected:
+		return "rejected"
+	}
+	return "unknown"
+}
+
+// Peek
+// returns the promise's value, error and
+// state without waiting for the promise
+// and without subscribing to it
+func (promise *Promise[T]) Peek() (T, error, State) {
+	promise.resultMutex.RLock()
+	defer promise.resultMutex.RUnlock()
+	if !promise.settled.Load() {
+		var obj T
+		return obj, nil, Pending
+	}
+	if promise.err != nil {
+		return promise.obj, promise.err, Rejected
+	}
+	return promise.obj, nil, Fulfilled
+}
+
+// State
+// returns the promise's state without
+// waiting for the promise
+func (promise *Promise[T]) State() State {
+	_, _, state := promise.Peek()
+	return state
+}
@@ -0,0 +1,343 @@
+package promise
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// The kinds of steps that settle
+// promises and subscribe to them
+const (
+	stepPromisify = "promisify"
+	stepValue     = "value"
+	stepLazy      = "lazy"
+	stepContext   = "context"
+	stepDeferred  = "deferred"
+	stepThen      = "then"
+	stepCatch     = "catch"
+	stepTap       = "tap"
+	stepFinally   = "finally"
+	stepExec      = "exec"
+	stepAwait     = "await"
+)
+
+// TraceEventKind is the kind of
+// a TraceEvent
+type TraceEventKind string
+
+const (
+	// TraceCreate is the kind of the event
+	// sent when a promise is created
+	TraceCreate TraceEventKind = "create"
+	// TraceStart is the kind of the event sent
+	// when the step that settles a promise starts
+	TraceStart TraceEventKind = "start"
+	// TraceSettle is the kind of the event
+	// sent when a promise is settled
+	TraceSettle TraceEventKind = "settle"
+	// TraceSubscribe is the kind of the event
+	// sent when a promise is subscribed to
+	TraceSubscribe TraceEventKind = "subscribe"
+	// TracePanic is the kind of the event sent when
+	// the step or a subscriber of a promise panics
+	TracePanic TraceEventKind = "panic"
+)
+
+// Tracer is notified about the lifecycle of
+// the promises created while it's set with
+// SetTracer. Its methods are called from the
+// promises' go routines so they must be safe
+// for concurrent use and shouldn't block
+type Tracer interface {
+	// OnCreate is called when a promise is created
+	OnCreate(event TraceEvent)
+	// OnStart is called when the step that
+	// settles a promise starts running
+	OnStart(event TraceEvent)
+	// OnSettle is called when a promise is
+	// fulfilled or rejected
+	OnSettle(event TraceEvent)
+	// OnSubscribe is called when Then, Catch,
+	// Finally, Exec or Await are called on a promise
+	OnSubscribe(event TraceEvent)
+	// OnPanic is called when the step that
+	// settles a promise or one of its Then
+	// and Catch subscribers panics
+	OnPanic(event TraceEvent)
+}
+
+// ContextTracer is a Tracer that adds values,
+// like spans, to the context of each promise.
+// The promises created from a promise with
+// Then and Catch inherit its context
+type ContextTracer interface {
+	Tracer
+	// TraceContext is called before OnCreate with
+	// the context the promise would inherit and
+	// returns the context of the promise
+	TraceContext(event TraceEvent) context.Context
+}
+
+// TraceEvent describes something
+// that happened to a promise
+type TraceEvent struct {
+	// Kind of the event
+	Kind TraceEventKind
+	// ID of the promise
+	ID uint64
+	// ParentID is the ID of the promise the
+	// promise was created from with Then or
+	// Catch, 0 if it wasn't created from one
+	ParentID uint64
+	// Step is the kind of step that settles the
+	// promise: promisify, value, lazy, context,
+	// deferred, then, catch or tap
+	Step string
+	// Name is the name of the promise
+	// given with Named
+	Name string
+	// Subscriber is the kind of subscriber of
+	// TraceSubscribe events: then, catch,
+	// finally, exec or await. It's also the
+	// subscriber that panicked of TracePanic
+	// events, empty if the step panicked
+	Subscriber string
+	// Time is when the event happened
+	// according to the package's Clock
+	Time time.Time
+	// State of the promise
+	State State
+	// Err is the error of a rejected promise
+	Err error
+	// Panic is the value recovered
+	// in TracePanic events
+	Panic any
+	// Context is the context of the promise:
+	// the context given to PromisifyContext or
+	// the context of the promise it was created
+	// from, plus the values of a ContextTracer
+	Context context.Context
+}
+
+var (
+	tracerMutex sync.RWMutex
+	// tracer is the Tracer of the
+	// promises created from now on
+	tracer Tracer
+)
+
+// SetTracer
+// sets the Tracer that is notified about the
+// promises created from now on and returns the
+// previous one, nil stops tracing.
+// Promises created from a promise use the
+// Tracer of the promise they're created from
+func SetTracer(t Tracer) Tracer {
+	tracerMutex.Lock()
+	defer tracerMutex.Unlock()
+	previous := tracer
+	tracer = t
+	return previous
+}
+
+// currentTracer
+// returns the Tracer of the package
+func currentTracer() Tracer {
+	tracerMutex.RLock()
+	defer tracerMutex.RUnlock()
+	return tracer
+}
+
+// ID
+// returns the id of the promise
+// used in its TraceEvents
+func (promise *Promise[T]) ID() uint64 {
+	return promise.id
+}
+
+// Context
+// returns the context of the promise, see
+// TraceEvent.Context. Ideal for passing the
+// context of a traced chain to PromisifyContext
+func (promise *Promise[T]) Context() context.Context {
+	return promise.ctx
+}
+
+// event
+// creates an event of the promise
+func (promise *Promise[T]) event(kind TraceEventKind) TraceEvent {
+	return TraceEvent{
+		Kind:     kind,
+		ID:       promise.id,
+		ParentID: promise.parentID,
+		Step:     promise.step,
+		Name:     promise.Name(),
+		Time:     currentClock().Now(),
+		Context:  promise.ctx,
+	}
+}
+
+// created
+// tracks and counts the promise and
+// notifies the tracer that it was created
+func (promise *Promise[T]) created() {
+	promise.track()
+	promise.reportCreated()
+	if promise.tracer == nil {
+		return
+	}
+	if tracer, ok := promise.tracer.(ContextTracer); ok {
+		promise.ctx = tracer.TraceContext(promise.event(TraceCreate))
+	}
+	promise.tracer.OnCreate(promise.event(TraceCreate))
+}
+
+// traceStart
+// notifies the tracer that the step
+// that settles the promise started
+func (promise *Promise[T]) traceStart() {
+	if promise.tracer != nil {
+		promise.tracer.OnStart(promise.event(TraceStart))
+	}
+}
+
+// traceSettle
+// notifies the tracer that the
+// promise was settled
+func (promise *Promise[T]) traceSettle(err error) {
+	if promise.tracer == nil {
+		return
+	}
+	event := promise.event(TraceSettle)
+	event.State = Fulfilled
+	if err != nil {
+		event.State = Rejected
+		event.Err = err
+	}
+	promise.tracer.OnSettle(event)
+}
+
+// subscribed
+// notifies the tracer that the promise
+// was subscribed to by subscriber
+func (promise *Promise[T]) subscribed(subscriber string) {
+	if promise.tracer != nil {
+		event := promise.event(TraceSubscribe)
+		event.Subscriber = subscriber
+		event.State = promise.State()
+		promise.tracer.OnSubscribe(event)
+	}
+}
+
+// tracePanic
+// notifies the tracer that the step or
+// the subscriber of the promise panicked
+func (promise *Promise[T]) tracePanic(r any, err error, subscriber string) {
+	if promise.tracer != nil {
+		event := promise.event(TracePanic)
+		event.Subscriber = subscriber
+		event.Panic = r
+		event.Err = err
+		event.State = promise.State()
+		promise.tracer.OnPanic(event)
+	}
+}
+
+// TraceRecorder is a Tracer that keeps
+// the events in memory, ideal for tests
+type TraceRecorder struct {
+	mutex  sync.Mutex
+	events []TraceEvent
+}
+
+// NewTraceRecorder
+// Creates an empty TraceRecorder
+func NewTraceRecorder() *TraceRecorder {
+	return &TraceRecorder{}
+}
+
+// record
+// stores an event
+func (recorder *TraceRecorder) record(event TraceEvent) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.events = append(recorder.events, event)
+}
+
+// OnCreate
+// records a TraceCreate event
+func (recorder *TraceRecorder) OnCreate(event TraceEvent) {
+	recorder.record(event)
+}
+
+// OnStart
+// records a TraceStart event
+func (recorder *TraceRecorder) OnStart(event TraceEvent) {
+	recorder.record(event)
+}
+
+// OnSettle
+// records a TraceSettle event
+func (recorder *TraceRecorder) OnSettle(event TraceEvent) {
+	recorder.record(event)
+}
+
+// OnSubscribe
+// records a TraceSubscribe event
+func (recorder *TraceRecorder) OnSubscribe(event TraceEvent) {
+	recorder.record(event)
+}
+
+// OnPanic
+// records a TracePanic event
+func (recorder *TraceRecorder) OnPanic(event TraceEvent) {
+	recorder.record(event)
+}
+
+// Events
+// returns the recorded events in the
+// order they were recorded
+func (recorder *TraceRecorder) Events() []TraceEvent {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	return append([]TraceEvent(nil), recorder.events...)
+}
+
+// EventsOf
+// returns the recorded events of
+// the promise with id
+func (recorder *TraceRecorder) EventsOf(id uint64) []TraceEvent {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	events := make([]TraceEvent, 0)
+	for _, event := range recorder.events {
+		if event.ID == id {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Children
+// returns the ids of the promises that
+// were created from the promise with id
+func (recorder *TraceRecorder) Children(id uint64) []uint64 {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	children := make([]uint64, 0)
+	for _, event := range recorder.events {
+		if event.Kind == TraceCreate && event.ParentID == id {
+			children = append(children, event.ID)
+		}
+	}
+	return children
+}
+
+// Reset
+// removes the recorded events
+func (recorder *TraceRecorder) Reset() {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.events = nil
+}
@@ -0,0 +1,154 @@
+package promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func useTraceRecorder(t *testing.T) *TraceRecorder {
+	recorder := NewTraceRecorder()
+	previous := SetTracer(recorder)
+	t.Cleanup(func() {
+		SetTracer(previous)
+	})
+	return recorder
+}
+
+type traceKey struct{}
+
+// contextTracer stores the id of each
+// promise in its context
+type contextTracer struct {
+	*TraceRecorder
+	mutex   sync.Mutex
+	parents map[uint64]any
+}
+
+func (tracer *contextTracer) TraceContext(event TraceEvent) context.Context {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	if tracer.parents == nil {
+		tracer.parents = make(map[uint64]any)
+	}
+	tracer.parents[event.ID] = event.Context.Value(traceKey{})
+	return context.WithValue(event.Context, traceKey{}, fmt.Sprint(event.ID))
+}
+
+func eventsOfKind(events []TraceEvent, kind TraceEventKind) []TraceEvent {
+	filtered := make([]TraceEvent, 0)
+	for _, event := range events {
+		if event.Kind == kind {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("Records the lifecycle of a chain", func(t *testing.T) {
+		recorder := useTraceRecorder(t)
+		p := Promisify[testMessage](func() (testMessage, error) {
+			return testMessage{Name: "Someone famous"}, nil
+		})
+		p1 := Then(p, func(tm testMessage) (string, error) {
+			return tm.Name, nil
+		})
+		name, err := p1.Await()
+		assert.Nil(t, err)
+		assert.Equal(t, name, "Someone famous")
+
+		events := recorder.EventsOf(p.ID())
+		assert.Len(t, events, 4)
+		assert.Equal(t, events[0].Kind, TraceCreate)
+		assert.Equal(t, events[len(events)-1].Kind, TraceSettle)
+		assert.Equal(t, events[len(events)-1].State, Fulfilled)
+		for _, event := range events {
+			assert.Equal(t, event.Step, stepPromisify)
+			assert.Zero(t, event.ParentID)
+		}
+		assert.Equal(t, eventsOfKind(events, TraceSubscribe)[0].Subscriber, stepThen)
+
+		events = recorder.EventsOf(p1.ID())
+		assert.Len(t, events, 4)
+		assert.Equal(t, events[0].Kind, TraceCreate)
+		assert.Equal(t, events[len(events)-1].Kind, TraceSettle)
+		for _, event := range events {
+			assert.Equal(t, event.Step, stepThen)
+			assert.Equal(t, event.ParentID, p.ID())
+		}
+		assert.Len(t, eventsOfKind(events, TraceStart), 1)
+		assert.Equal(t, eventsOfKind(events, TraceSubscribe)[0].Subscriber, stepAwait)
+		assert.Equal(t, recorder.Children(p.ID()), []uint64{p1.ID()})
+	})
+	t.Run("Records subscriptions and rejections", func(t *testing.T) {
+		recorder := useTraceRecorder(t)
+		p := Promisify[testMessage](func() (testMessage, error) {
+			return testMessage{}, errors.New("Famous people don't shake hands")
+		})
+		p1 := Catch(p, func(err error) (testMessage, error) {
+			return testMessage{Name: "Stunt Double"}, nil
+		})
+		p1.Await()
+
+		events := recorder.EventsOf(p.ID())
+		assert.Equal(t, eventsOfKind(events, TraceSubscribe)[0].Subscriber, stepCatch)
+		settled := eventsOfKind(events, TraceSettle)
+		assert.Len(t, settled, 1)
+		assert.Equal(t, settled[0].State, Rejected)
+		assert.EqualError(t, settled[0].Err, "Famous people don't shake hands")
+		assert.Equal(t, recorder.EventsOf(p1.ID())[0].Step, stepCatch)
+	})
+	t.Run("Records panics", func(t *testing.T) {
+		recorder := useTraceRecorder(t)
+		p := Promisify[string](func() (string, error) {
+			panic("Famous people don't shake hands")
+		})
+		p.Then(func(string) {})
+		p.Catch(func(error) {
+			panic("Stunt Double")
+		})
+		p.Catch(func(error) {})
+		p.Exec()
+
+		panics := eventsOfKind(recorder.EventsOf(p.ID()), TracePanic)
+		assert.Len(t, panics, 2)
+		assert.Equal(t, panics[0].Panic, "Famous people don't shake hands")
+		assert.Empty(t, panics[0].Subscriber)
+		assert.Equal(t, panics[1].Panic, "Stunt Double")
+		assert.Equal(t, panics[1].Subscriber, stepCatch)
+		assert.Contains(t, panics[1].Err.Error(), "Stunt Double")
+	})
+	t.Run("Passes the context of a ContextTracer to derived promises", func(t *testing.T) {
+		tracer := &contextTracer{TraceRecorder: NewTraceRecorder()}
+		previous := SetTracer(tracer)
+		t.Cleanup(func() {
+			SetTracer(previous)
+		})
+		ctx := context.WithValue(context.Background(), traceKey{}, "root")
+		p := PromisifyContext[string](ctx, func(ctx context.Context) (string, error) {
+			return ctx.Value(traceKey{}).(string), nil
+		})
+		p1 := Then(p, func(id string) (string, error) {
+			return id, nil
+		})
+		id, err := p.Await()
+		assert.Nil(t, err)
+		assert.Equal(t, id, fmt.Sprint(p.ID()))
+		assert.Equal(t, p1.Context().Value(traceKey{}), fmt.Sprint(p1.ID()))
+		for _, event := range tracer.EventsOf(p1.ID()) {
+			assert.Equal(t, event.Context, p1.Context())
+		}
+		assert.Equal(t, tracer.parents, map[uint64]any{p.ID(): "root", p1.ID(): fmt.Sprint(p.ID())})
+	})
+	t.Run("Stops tracing when the tracer is unset", func(t *testing.T) {
+		recorder := useTraceRecorder(t)
+		SetTracer(nil)
+		Promisify[string]("Someone famous").Await()
+		assert.Empty(t, recorder.Events())
+	})
+}